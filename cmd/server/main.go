@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/vlah-software-house/etsy-mock-api/internal/handlers"
 	"github.com/vlah-software-house/etsy-mock-api/internal/middleware"
+	"github.com/vlah-software-house/etsy-mock-api/internal/openapi"
 	"github.com/vlah-software-house/etsy-mock-api/internal/seed"
 	"github.com/vlah-software-house/etsy-mock-api/internal/store"
 )
@@ -17,34 +26,97 @@ func main() {
 	noAuth := flag.Bool("no-auth", false, "Disable authentication checks")
 	noSeed := flag.Bool("no-seed", false, "Start with empty data store")
 	seedConfig := flag.String("seed-config", "", "Path to JSON seed config for generated content")
+	dataFile := flag.String("data-file", "", "Path to a JSON file the store is loaded from on startup and saved to while running")
+	saveInterval := flag.Duration("save-interval", 30*time.Second, "How often to save the store to -data-file (0 saves only on shutdown)")
+	specFile := flag.String("spec", "", "Path to an Etsy OpenAPI document (e.g. etsy_oas.json) to validate requests against")
+	coverage := flag.Bool("coverage", false, "Print which -spec operations the mock implements, stubs or is missing, then exit")
+	verifyResponses := flag.Bool("verify-responses", false, "Check response bodies against the -spec document and report drift in logs and an X-Mock-Contract-Violations header")
 	flag.Parse()
 
 	s := store.New()
+	loaded := false
+	if *dataFile != "" {
+		err := s.LoadFile(*dataFile)
+		switch {
+		case err == nil:
+			loaded = true
+			log.Printf("Loaded data store from %s", *dataFile)
+		case errors.Is(err, os.ErrNotExist):
+			log.Printf("Data file %s does not exist yet; it will be created", *dataFile)
+		default:
+			log.Fatalf("Failed to load data file: %v", err)
+		}
+	}
+
+	// seedFn is kept so /admin/reset can rebuild the store the same way.
+	var seedFn func(*store.Store)
 	if *seedConfig != "" {
 		cfg, err := seed.LoadConfig(*seedConfig)
 		if err != nil {
 			log.Fatalf("Failed to load seed config: %v", err)
 		}
-		seed.GenerateFromConfig(s, cfg)
-		log.Printf("Generated seed data from %s (%d shops)", *seedConfig, cfg.Shops)
+		seedFn = func(s *store.Store) { seed.GenerateFromConfig(s, cfg) }
+		if !loaded {
+			seedFn(s)
+			log.Printf("Generated seed data from %s (%d shops)", *seedConfig, cfg.Shops)
+		}
 	} else if !*noSeed {
-		seed.Load(s)
-		log.Println("Seed data loaded")
+		seedFn = seed.Load
+		if !loaded {
+			seedFn(s)
+			log.Println("Seed data loaded")
+		}
+	}
+
+	var spec *openapi.Spec
+	if *specFile != "" {
+		var err error
+		spec, err = openapi.Load(*specFile)
+		if err != nil {
+			log.Fatalf("Failed to load OpenAPI document: %v", err)
+		}
+		log.Printf("Validating requests against %s (%d operations)", *specFile, len(spec.Operations))
+	}
+	if *coverage {
+		if spec == nil {
+			log.Fatal("-coverage requires the OpenAPI document given by -spec")
+		}
+		printCoverage(os.Stdout, handlers.Coverage(spec, nil))
+		return
 	}
 
 	tokenStore := middleware.NewTokenStore()
 	keyStore := middleware.NewAPIKeyStore()
-	h := handlers.New(s, tokenStore)
+	h := handlers.New(s, tokenStore, keyStore, seedFn)
+	if spec != nil {
+		h.Spec = spec
+		h.Hits = openapi.NewHits()
+	}
 	mux := http.NewServeMux()
 	h.RegisterRoutes(mux)
 
 	var handler http.Handler = mux
+	if *verifyResponses {
+		if spec == nil {
+			log.Fatal("-verify-responses requires the OpenAPI document given by -spec")
+		}
+		handler = middleware.VerifyResponses(spec)(handler)
+		log.Println("Verifying responses against the OpenAPI document")
+	}
 	handler = middleware.JSONContent(handler)
-	handler = middleware.RateLimitHeaders(handler)
+	if spec != nil {
+		handler = middleware.ValidateRequests(spec)(handler)
+	}
+	handler = middleware.RateLimit(keyStore)(handler)
 	if !*noAuth {
 		handler = middleware.MockAuth(tokenStore, keyStore)(handler)
 	}
+	handler = middleware.Faults(h.Faults)(handler)
+	handler = middleware.MockControl(handler)
 	handler = middleware.CORS(handler)
+	if h.Hits != nil {
+		handler = middleware.CountOperations(spec, h.Hits)(handler)
+	}
 	handler = middleware.RequestLogger(handler)
 
 	addr := fmt.Sprintf(":%d", *port)
@@ -58,10 +130,63 @@ func main() {
 		log.Println("Authentication: enabled (x-api-key format: keystring:shared_secret)")
 		log.Println("Pre-seeded API keys: test-key:test-secret, alice-app:alice-secret, bob-app:bob-secret")
 		log.Println("Banned API key: banned-app:banned-secret | Expired: expired-app:expired-secret")
+		log.Println("Throttled API key (2/s, 20/day): throttled-app:throttled-secret")
 		log.Println("Pre-seeded OAuth tokens: test-token-alice (user 1001), test-token-bob (user 1002)")
 	}
 
-	if err := http.ListenAndServe(addr, handler); err != nil {
-		log.Fatalf("Server failed: %v", err)
+	srv := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	if *dataFile != "" && *saveInterval > 0 {
+		saveUntilSignal(s, *dataFile, *saveInterval, sig)
+	} else {
+		<-sig
+	}
+
+	log.Println("Shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Graceful shutdown failed: %v", err)
+	}
+	if *dataFile != "" {
+		if err := s.SaveFile(*dataFile); err != nil {
+			log.Fatalf("Failed to save data file: %v", err)
+		}
+		log.Printf("Data store saved to %s", *dataFile)
+	}
+}
+
+// printCoverage writes report as a table followed by its totals.
+func printCoverage(out io.Writer, report handlers.CoverageReport) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tMETHOD\tPATH\tOPERATION")
+	for _, op := range report.Results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", op.Status, op.Method, op.Path, op.OperationID)
+	}
+	tw.Flush()
+	fmt.Fprintf(out, "\n%d operations: %d implemented, %d stubbed, %d missing\n",
+		report.Count, report.Implemented, report.Stubbed, report.Missing)
+}
+
+// saveUntilSignal writes the store to path every interval until a signal arrives.
+func saveUntilSignal(s *store.Store, path string, interval time.Duration, sig <-chan os.Signal) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.SaveFile(path); err != nil {
+				log.Printf("Failed to save data file: %v", err)
+			}
+		case <-sig:
+			return
+		}
 	}
 }
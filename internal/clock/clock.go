@@ -0,0 +1,106 @@
+// Package clock is the mock's source of the current time. It follows the
+// wall clock until an admin freezes, sets or advances it, which lets tests
+// expire tokens and listings without waiting.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a settable clock. The zero value follows the wall clock.
+type Clock struct {
+	mu     sync.RWMutex
+	frozen bool
+	at     time.Time     // the time while frozen
+	offset time.Duration // added to the wall clock while running
+}
+
+// Default is the clock every timestamp in the mock comes from.
+var Default = &Clock{}
+
+// Now returns the current time of Default.
+func Now() time.Time {
+	return Default.Now()
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.frozen {
+		return c.at
+	}
+	return time.Now().Add(c.offset)
+}
+
+// Set moves the clock to t. A running clock keeps running from t.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frozen {
+		c.at = t
+		return
+	}
+	c.offset = time.Until(t)
+}
+
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frozen {
+		c.at = c.at.Add(d)
+		return
+	}
+	c.offset += d
+}
+
+// Freeze stops the clock at its current time.
+func (c *Clock) Freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.frozen {
+		c.at = time.Now().Add(c.offset)
+		c.frozen = true
+	}
+}
+
+// Resume lets a frozen clock run again from the time it shows.
+func (c *Clock) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.frozen {
+		c.offset = time.Until(c.at)
+		c.frozen = false
+	}
+}
+
+// Reset goes back to following the wall clock.
+func (c *Clock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frozen = false
+	c.at = time.Time{}
+	c.offset = 0
+}
+
+// State describes a clock for the admin API.
+type State struct {
+	Now           int64 `json:"now"`
+	Frozen        bool  `json:"frozen"`
+	OffsetSeconds int64 `json:"offset_seconds"` // how far the clock is ahead of the wall clock
+}
+
+func (c *Clock) State() State {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	wall := time.Now()
+	now := wall.Add(c.offset)
+	if c.frozen {
+		now = c.at
+	}
+	return State{
+		Now:           now.Unix(),
+		Frozen:        c.frozen,
+		OffsetSeconds: int64(now.Sub(wall).Round(time.Second) / time.Second),
+	}
+}
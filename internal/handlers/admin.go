@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
+	"github.com/vlah-software-house/etsy-mock-api/internal/middleware"
+	"github.com/vlah-software-house/etsy-mock-api/internal/models"
+	"github.com/vlah-software-house/etsy-mock-api/internal/store"
+)
+
+// POST /admin/reset — rebuild the store as it was at startup
+func (h *Handler) AdminReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "POST only")
+		return
+	}
+
+	fresh := store.New()
+	if h.Seed != nil {
+		h.Seed(fresh)
+	}
+	h.Store.Replace(fresh)
+	h.TokenStore.Reset()
+	h.KeyStore.Reset()
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reset"})
+}
+
+// GET /admin/snapshots
+func (h *Handler) ListSnapshots(w http.ResponseWriter, r *http.Request) {
+	snaps := h.Snapshots.List()
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   len(snaps),
+		Results: snaps,
+	})
+}
+
+// POST /admin/snapshots/{name}
+func (h *Handler) CreateSnapshot(w http.ResponseWriter, r *http.Request, name string) {
+	info, err := h.Snapshots.Save(name, h.Store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to snapshot store: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// POST /admin/snapshots/{name}/restore
+func (h *Handler) RestoreSnapshot(w http.ResponseWriter, r *http.Request, name string) {
+	found, err := h.Snapshots.Restore(name, h.Store)
+	if !found {
+		writeError(w, http.StatusNotFound, "Snapshot not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to restore snapshot: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "restored", "name": name})
+}
+
+// DELETE /admin/snapshots/{name}
+func (h *Handler) DeleteSnapshot(w http.ResponseWriter, r *http.Request, name string) {
+	if !h.Snapshots.Delete(name) {
+		writeError(w, http.StatusNotFound, "Snapshot not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/faults
+func (h *Handler) ListFaults(w http.ResponseWriter, r *http.Request) {
+	rules := h.Faults.List()
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   len(rules),
+		Results: rules,
+	})
+}
+
+// POST /admin/faults
+func (h *Handler) CreateFault(w http.ResponseWriter, r *http.Request) {
+	var rule middleware.FaultRule
+	if err := decodeJSON(r, &rule); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateFault(rule); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	writeJSON(w, http.StatusCreated, h.Faults.Add(rule))
+}
+
+// DELETE /admin/faults
+func (h *Handler) ClearFaults(w http.ResponseWriter, r *http.Request) {
+	h.Faults.Clear()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DELETE /admin/faults/{fault_id}
+func (h *Handler) DeleteFault(w http.ResponseWriter, r *http.Request) {
+	id, ok := extractPathID(r.URL.Path, "faults")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid fault_id")
+		return
+	}
+	if !h.Faults.Delete(id) {
+		writeError(w, http.StatusNotFound, "Fault rule not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateFault(rule middleware.FaultRule) string {
+	switch strings.ToUpper(rule.Method) {
+	case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return "method must be one of GET, POST, PUT, PATCH, DELETE"
+	}
+	switch {
+	case strings.Trim(rule.Path, "/") == "":
+		return "path is required"
+	case rule.Status == 0 && rule.DelayMs == 0 && !rule.Drop:
+		return "A fault rule needs a status, delay_ms or drop_connection"
+	case rule.Status != 0 && rule.Drop:
+		return "status and drop_connection cannot be combined"
+	case rule.Status != 0 && (rule.Status < 400 || rule.Status > 599):
+		return "status must be between 400 and 599"
+	case rule.DelayMs < 0:
+		return "delay_ms must be at least 0"
+	case rule.RetryAfter < 0:
+		return "retry_after must be at least 0"
+	case rule.Percent < 0 || rule.Percent > 100:
+		return "percent must be between 0 and 100"
+	case rule.NthCall < 0:
+		return "nth_call must be at least 0"
+	}
+	return ""
+}
+
+// GET /admin/clock
+func (h *Handler) GetClock(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, clock.Default.State())
+}
+
+// POST /admin/clock — set, advance, freeze or resume the mock's clock
+func (h *Handler) UpdateClock(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Set     *int64  `json:"set"`     // unix timestamp to move to
+		Advance *string `json:"advance"` // duration such as "90m" or "48h"
+		Frozen  *bool   `json:"frozen"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if body.Set == nil && body.Advance == nil && body.Frozen == nil {
+		writeError(w, http.StatusBadRequest, "Provide at least one of set, advance or frozen")
+		return
+	}
+	var advance time.Duration
+	if body.Advance != nil {
+		d, err := time.ParseDuration(*body.Advance)
+		if err != nil || d < 0 {
+			writeError(w, http.StatusBadRequest, "advance must be a positive duration such as 90m or 48h")
+			return
+		}
+		advance = d
+	}
+
+	if body.Set != nil {
+		clock.Default.Set(time.Unix(*body.Set, 0))
+	}
+	clock.Default.Advance(advance)
+	if body.Frozen != nil {
+		if *body.Frozen {
+			clock.Default.Freeze()
+		} else {
+			clock.Default.Resume()
+		}
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		clock.State
+		Effects store.TimeEffects `json:"effects"`
+	}{clock.Default.State(), h.Store.CatchUp()})
+}
+
+// DELETE /admin/clock — follow the wall clock again
+func (h *Handler) ResetClock(w http.ResponseWriter, r *http.Request) {
+	clock.Default.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
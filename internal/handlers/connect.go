@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
+	"github.com/vlah-software-house/etsy-mock-api/internal/middleware"
+	"github.com/vlah-software-house/etsy-mock-api/internal/models"
+)
+
+// authCodeTTL is how long an authorization code can be exchanged for.
+const authCodeTTL = 5 * time.Minute
+
+// connectRequest holds the authorization request parameters of
+// /oauth/connect. The consent form posts them back as hidden fields.
+type connectRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+func readConnectRequest(form url.Values) connectRequest {
+	return connectRequest{
+		ResponseType:        form.Get("response_type"),
+		ClientID:            form.Get("client_id"),
+		RedirectURI:         form.Get("redirect_uri"),
+		Scope:               form.Get("scope"),
+		State:               form.Get("state"),
+		CodeChallenge:       form.Get("code_challenge"),
+		CodeChallengeMethod: form.Get("code_challenge_method"),
+	}
+}
+
+// GET /oauth/connect — consent page; POST submits the tester's choice
+func (h *Handler) OAuthConnect(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		req := readConnectRequest(r.URL.Query())
+		if !h.checkConnectRequest(w, r, req) {
+			return
+		}
+		renderConsent(w, http.StatusOK, req, h.Store.GetUsers(), "")
+	case http.MethodPost:
+		h.submitConsent(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *Handler) submitConsent(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		renderConnectError(w, "Invalid form data")
+		return
+	}
+	req := readConnectRequest(r.PostForm)
+	if !h.checkConnectRequest(w, r, req) {
+		return
+	}
+	if r.PostFormValue("decision") != "approve" {
+		redirectWith(w, r, req.RedirectURI, url.Values{
+			"error":             {"access_denied"},
+			"error_description": {"The user denied the request"},
+			"state":             {req.State},
+		})
+		return
+	}
+
+	userID, ok := parseID(r.PostFormValue("user_id"))
+	if ok {
+		_, ok = h.Store.GetUser(userID)
+	}
+	if !ok {
+		renderConsent(w, http.StatusBadRequest, req, h.Store.GetUsers(), "Pick a user to sign in as")
+		return
+	}
+
+	b := make([]byte, 24)
+	rand.Read(b)
+	code := hex.EncodeToString(b)
+	h.TokenStore.StoreCode(&middleware.AuthCode{
+		Code:          code,
+		ClientID:      req.ClientID,
+		RedirectURI:   req.RedirectURI,
+		UserID:        userID,
+		Scope:         req.Scope,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     clock.Now().Add(authCodeTTL),
+	})
+	redirectWith(w, r, req.RedirectURI, url.Values{"code": {code}, "state": {req.State}})
+}
+
+// checkConnectRequest validates an authorization request. Until client_id
+// and redirect_uri are known to be good, problems are shown as an error
+// page; after that they are sent back to the client on its redirect_uri, as
+// OAuth 2.0 requires. Returns false if a response was written.
+func (h *Handler) checkConnectRequest(w http.ResponseWriter, r *http.Request, req connectRequest) bool {
+	if req.ClientID == "" {
+		renderConnectError(w, "client_id is required")
+		return false
+	}
+	if entry, ok := h.KeyStore.Get(req.ClientID); !ok || entry.Status != middleware.APIKeyValid {
+		renderConnectError(w, "client_id is not a valid API keystring")
+		return false
+	}
+	if u, err := url.Parse(req.RedirectURI); err != nil || u.Scheme == "" || u.Host == "" {
+		renderConnectError(w, "redirect_uri must be an absolute URL")
+		return false
+	}
+
+	fail := func(code, desc string) bool {
+		redirectWith(w, r, req.RedirectURI, url.Values{
+			"error":             {code},
+			"error_description": {desc},
+			"state":             {req.State},
+		})
+		return false
+	}
+	switch {
+	case req.ResponseType != "code":
+		return fail("unsupported_response_type", "response_type must be code")
+	case req.State == "":
+		return fail("invalid_request", "state is required")
+	case req.Scope == "":
+		return fail("invalid_request", "scope is required")
+	case unknownScope(req.Scope) != "":
+		return fail("invalid_scope", "Unknown scope: "+unknownScope(req.Scope))
+	case req.CodeChallenge == "":
+		return fail("invalid_request", "code_challenge is required")
+	case req.CodeChallengeMethod != "S256":
+		return fail("invalid_request", "code_challenge_method must be S256")
+	}
+	return true
+}
+
+// unknownScope returns the first scope in the space-separated list that
+// Etsy doesn't define, or "".
+func unknownScope(scope string) string {
+	known := make(map[string]bool)
+	for _, s := range middleware.AllScopes() {
+		known[s] = true
+	}
+	for _, s := range strings.Fields(scope) {
+		if !known[s] {
+			return s
+		}
+	}
+	return ""
+}
+
+// pkceMatches reports whether verifier hashes to the S256 challenge.
+func pkceMatches(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	want := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(want), []byte(challenge)) == 1
+}
+
+// redirectWith sends the browser to base with params added to its query.
+func redirectWith(w http.ResponseWriter, r *http.Request, base string, params url.Values) {
+	u, _ := url.Parse(base)
+	q := u.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			q[key] = values
+		}
+	}
+	u.RawQuery = q.Encode()
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+type consentUser struct {
+	UserID int64
+	Name   string
+	Email  string
+}
+
+func renderConsent(w http.ResponseWriter, status int, req connectRequest, users []models.User, problem string) {
+	list := make([]consentUser, len(users))
+	for i, u := range users {
+		list[i] = consentUser{UserID: u.UserID}
+		var name []string
+		if u.FirstName != nil {
+			name = append(name, *u.FirstName)
+		}
+		if u.LastName != nil {
+			name = append(name, *u.LastName)
+		}
+		list[i].Name = strings.Join(name, " ")
+		if u.PrimaryEmail != nil {
+			list[i].Email = *u.PrimaryEmail
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	consentPage.Execute(w, map[string]interface{}{
+		"Req":     req,
+		"Scopes":  strings.Fields(req.Scope),
+		"Users":   list,
+		"Problem": problem,
+	})
+}
+
+func renderConnectError(w http.ResponseWriter, msg string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	connectErrorPage.Execute(w, msg)
+}
+
+var consentPage = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Etsy Mock — Grant access</title></head>
+<body>
+<h1>Grant access to {{.Req.ClientID}}</h1>
+{{if .Problem}}<p id="problem" style="color:#b00">{{.Problem}}</p>{{end}}
+{{if .Scopes}}<p>Requested scopes:</p>
+<ul id="scopes">{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<form method="post" action="/oauth/connect">
+<input type="hidden" name="response_type" value="{{.Req.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Req.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Req.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Req.Scope}}">
+<input type="hidden" name="state" value="{{.Req.State}}">
+<input type="hidden" name="code_challenge" value="{{.Req.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Req.CodeChallengeMethod}}">
+<p>Sign in as:</p>
+{{range $i, $u := .Users}}<label><input type="radio" name="user_id" value="{{$u.UserID}}" id="user-{{$u.UserID}}"{{if eq $i 0}} checked{{end}}> {{$u.Name}} ({{$u.UserID}}{{if $u.Email}}, {{$u.Email}}{{end}})</label><br>
+{{end}}
+<p>
+<button type="submit" name="decision" value="approve" id="approve">Grant access</button>
+<button type="submit" name="decision" value="deny" id="deny">Deny</button>
+</p>
+</form>
+</body>
+</html>
+`))
+
+var connectErrorPage = template.Must(template.New("connect-error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Etsy Mock — Authorization error</title></head>
+<body>
+<h1>Authorization error</h1>
+<p id="error">{{.}}</p>
+</body>
+</html>
+`))
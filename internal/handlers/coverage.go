@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/middleware"
+	"github.com/vlah-software-house/etsy-mock-api/internal/models"
+	"github.com/vlah-software-house/etsy-mock-api/internal/openapi"
+	"github.com/vlah-software-house/etsy-mock-api/internal/store"
+)
+
+// Coverage statuses of a spec operation.
+const (
+	CoverageImplemented = "implemented"
+	CoverageStubbed     = "stubbed"
+	CoverageMissing     = "missing"
+)
+
+// stubbedOperations are routed but answer with canned data instead of
+// anything kept in the store.
+var stubbedOperations = map[string]bool{
+	"getShopProductionPartners": true, // always an empty list
+}
+
+type OperationCoverage struct {
+	OperationID string `json:"operation_id"`
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Status      string `json:"status"`
+	Hits        int    `json:"hits"`
+}
+
+type CoverageReport struct {
+	Implemented int                 `json:"implemented"`
+	Stubbed     int                 `json:"stubbed"`
+	Missing     int                 `json:"missing"`
+	Count       int                 `json:"count"`
+	Results     []OperationCoverage `json:"results"`
+}
+
+// Coverage lists every operation in spec with whether the mock serves it and
+// how many times hits says it was called. Whether an operation is served is
+// found by sending it through the routes against an empty store with no
+// credentials: the router's own 404 or 405 means nothing handles it.
+func Coverage(spec *openapi.Spec, hits map[string]int) CoverageReport {
+	probe := New(store.New(), middleware.NewTokenStore(), middleware.NewAPIKeyStore(), nil)
+	mux := http.NewServeMux()
+	probe.RegisterRoutes(mux)
+
+	report := CoverageReport{Results: []OperationCoverage{}}
+	for _, op := range spec.Operations {
+		status := CoverageImplemented
+		switch {
+		case !routed(mux, op):
+			status = CoverageMissing
+			report.Missing++
+		case stubbedOperations[op.OperationID]:
+			status = CoverageStubbed
+			report.Stubbed++
+		default:
+			report.Implemented++
+		}
+		report.Results = append(report.Results, OperationCoverage{
+			OperationID: op.OperationID,
+			Method:      op.Method,
+			Path:        op.Path,
+			Status:      status,
+			Hits:        hits[op.OperationID],
+		})
+	}
+	sort.Slice(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+	report.Count = len(report.Results)
+	return report
+}
+
+// routed reports whether mux has a handler for op, filling every path
+// parameter with 1.
+func routed(mux *http.ServeMux, op *openapi.Operation) bool {
+	segments := strings.Split(op.Path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			segments[i] = "1"
+		}
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(op.Method, strings.Join(segments, "/"), nil))
+
+	switch rec.Code {
+	case http.StatusMethodNotAllowed:
+		return false
+	case http.StatusNotFound:
+		var body models.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			return false // the mux's own plain-text 404
+		}
+		return body.Error != "Endpoint not found"
+	}
+	return true
+}
+
+// GET /admin/coverage — DELETE clears the hit counts
+func (h *Handler) AdminCoverage(w http.ResponseWriter, r *http.Request) {
+	if h.Spec == nil {
+		writeError(w, http.StatusNotFound, "Coverage needs the OpenAPI document given by -spec")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, Coverage(h.Spec, h.Hits.Counts()))
+	case http.MethodDelete:
+		h.Hits.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 )
 
@@ -67,8 +67,8 @@ func (h *Handler) UpdateListingPersonalization(w http.ResponseWriter, r *http.Re
 	if body.PersonalizationInstructions != nil {
 		listing.PersonalizationInstructions = body.PersonalizationInstructions
 	}
-	listing.UpdatedTimestamp = time.Now().Unix()
-	listing.LastModifiedTimestamp = time.Now().Unix()
+	listing.UpdatedTimestamp = clock.Now().Unix()
+	listing.LastModifiedTimestamp = clock.Now().Unix()
 
 	writeJSON(w, http.StatusOK, models.ListingPersonalization{
 		IsPersonalizable:            listing.IsPersonalizable,
@@ -184,6 +184,12 @@ func (h *Handler) DeleteListingVideo(w http.ResponseWriter, r *http.Request) {
 	writeError(w, http.StatusNotFound, "Video not found")
 }
 
+// translationLanguages are the languages Etsy accepts listing translations in.
+var translationLanguages = map[string]bool{
+	"de": true, "en": true, "es": true, "fr": true, "it": true,
+	"ja": true, "nl": true, "pl": true, "pt": true, "ru": true,
+}
+
 // GET /v3/application/shops/{shop_id}/listings/{listing_id}/translations/{language}
 func (h *Handler) GetListingTranslation(w http.ResponseWriter, r *http.Request) {
 	listingID, ok := extractPathID(r.URL.Path, "listings")
@@ -191,37 +197,70 @@ func (h *Handler) GetListingTranslation(w http.ResponseWriter, r *http.Request)
 		writeError(w, http.StatusBadRequest, "Invalid listing_id")
 		return
 	}
-	listing, found := h.Store.GetListing(listingID)
-	if !found {
+	lang := extractPathSegment(r.URL.Path, "translations")
+	if !translationLanguages[lang] {
+		writeError(w, http.StatusBadRequest, "Unsupported language: "+lang)
+		return
+	}
+	if _, found := h.Store.GetListing(listingID); !found {
 		writeError(w, http.StatusNotFound, "Listing not found")
 		return
 	}
-	lang := extractPathSegment(r.URL.Path, "translations")
-	writeJSON(w, http.StatusOK, models.ListingTranslation{
-		ListingID:   listingID,
-		Language:    lang,
-		Title:       &listing.Title,
-		Description: &listing.Description,
-		Tags:        listing.Tags,
-	})
+	t, found := h.Store.GetListingTranslation(listingID, lang)
+	if !found {
+		writeError(w, http.StatusNotFound, "Translation not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// POST /v3/application/shops/{shop_id}/listings/{listing_id}/translations/{language}
+func (h *Handler) CreateListingTranslation(w http.ResponseWriter, r *http.Request) {
+	t, ok := h.decodeListingTranslation(w, r)
+	if !ok {
+		return
+	}
+	if !h.Store.CreateListingTranslation(t) {
+		writeError(w, http.StatusConflict, "A translation for this listing already exists in language "+t.Language)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
 }
 
 // PUT /v3/application/shops/{shop_id}/listings/{listing_id}/translations/{language}
 func (h *Handler) UpdateListingTranslation(w http.ResponseWriter, r *http.Request) {
-	if !requireScope(w, r, "listings_w") {
+	t, ok := h.decodeListingTranslation(w, r)
+	if !ok {
 		return
 	}
+	if !h.Store.UpdateListingTranslation(t) {
+		writeError(w, http.StatusNotFound, "Translation not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// decodeListingTranslation performs the checks shared by create and update
+// and builds the translation from the request. On failure it writes the
+// error response and returns false.
+func (h *Handler) decodeListingTranslation(w http.ResponseWriter, r *http.Request) (*models.ListingTranslation, bool) {
+	if !requireScope(w, r, "listings_w") {
+		return nil, false
+	}
 	listingID, ok := extractPathID(r.URL.Path, "listings")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid listing_id")
-		return
+		return nil, false
 	}
-	listing, found := h.Store.GetListing(listingID)
-	if !found {
+	lang := extractPathSegment(r.URL.Path, "translations")
+	if !translationLanguages[lang] {
+		writeError(w, http.StatusBadRequest, "Unsupported language: "+lang)
+		return nil, false
+	}
+	if _, found := h.Store.GetListing(listingID); !found {
 		writeError(w, http.StatusNotFound, "Listing not found")
-		return
+		return nil, false
 	}
-	lang := extractPathSegment(r.URL.Path, "translations")
 
 	var body struct {
 		Title       *string  `json:"title"`
@@ -230,42 +269,39 @@ func (h *Handler) UpdateListingTranslation(w http.ResponseWriter, r *http.Reques
 	}
 	if err := decodeJSON(r, &body); err != nil {
 		writeError(w, http.StatusBadRequest, "Invalid request body")
-		return
-	}
-
-	title := listing.Title
-	desc := listing.Description
-	tags := listing.Tags
-	if body.Title != nil {
-		title = *body.Title
+		return nil, false
 	}
-	if body.Description != nil {
-		desc = *body.Description
+	if body.Title == nil || body.Description == nil {
+		writeError(w, http.StatusBadRequest, "title and description are required")
+		return nil, false
 	}
-	if body.Tags != nil {
-		tags = body.Tags
+	if body.Tags == nil {
+		body.Tags = []string{}
 	}
-
-	writeJSON(w, http.StatusOK, models.ListingTranslation{
+	return &models.ListingTranslation{
 		ListingID:   listingID,
 		Language:    lang,
-		Title:       &title,
-		Description: &desc,
-		Tags:        tags,
-	})
+		Title:       body.Title,
+		Description: body.Description,
+		Tags:        body.Tags,
+	}, true
 }
 
 // GET /v3/application/shops/{shop_id}/listings/{listing_id}/variation-images
 func (h *Handler) GetListingVariationImages(w http.ResponseWriter, r *http.Request) {
-	_, ok := extractPathID(r.URL.Path, "listings")
+	listingID, ok := extractPathID(r.URL.Path, "listings")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid listing_id")
 		return
 	}
-	// Return empty set by default
+	if _, found := h.Store.GetListing(listingID); !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+	vis := h.Store.GetListingVariationImages(listingID)
 	writeJSON(w, http.StatusOK, models.PaginatedResponse{
-		Count:   0,
-		Results: []models.ListingVariationImage{},
+		Count:   len(vis),
+		Results: vis,
 	})
 }
 
@@ -274,11 +310,15 @@ func (h *Handler) UpdateListingVariationImages(w http.ResponseWriter, r *http.Re
 	if !requireScope(w, r, "listings_w") {
 		return
 	}
-	_, ok := extractPathID(r.URL.Path, "listings")
+	listingID, ok := extractPathID(r.URL.Path, "listings")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid listing_id")
 		return
 	}
+	if _, found := h.Store.GetListing(listingID); !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
 	var body struct {
 		VariationImages []models.ListingVariationImage `json:"variation_images"`
 	}
@@ -289,51 +329,286 @@ func (h *Handler) UpdateListingVariationImages(w http.ResponseWriter, r *http.Re
 	if body.VariationImages == nil {
 		body.VariationImages = []models.ListingVariationImage{}
 	}
+	if msg := h.validateVariationImages(listingID, body.VariationImages); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	h.Store.SetListingVariationImages(listingID, body.VariationImages)
 	writeJSON(w, http.StatusOK, models.PaginatedResponse{
 		Count:   len(body.VariationImages),
 		Results: body.VariationImages,
 	})
 }
 
+// validateVariationImages checks that every mapping points at one of the
+// listing's images and at a property value used by its inventory. It returns
+// an error message, or "" if the mappings are valid.
+func (h *Handler) validateVariationImages(listingID int64, vis []models.ListingVariationImage) string {
+	images := make(map[int64]bool)
+	for _, img := range h.Store.GetListingImages(listingID) {
+		images[img.ListingImageID] = true
+	}
+	values := make(map[[2]int64]bool)
+	if inv, ok := h.Store.GetListingInventory(listingID); ok {
+		for _, p := range inv.Products {
+			if p.IsDeleted {
+				continue
+			}
+			for _, pv := range p.PropertyValues {
+				for _, vid := range pv.ValueIDs {
+					values[[2]int64{pv.PropertyID, int64(vid)}] = true
+				}
+			}
+		}
+	}
+
+	seen := make(map[[2]int64]bool)
+	for i, vi := range vis {
+		if i > 0 && vi.PropertyID != vis[0].PropertyID {
+			return "All variation images must use the same property_id"
+		}
+		key := [2]int64{vi.PropertyID, vi.ValueID}
+		if !values[key] {
+			return fmt.Sprintf("variation_images[%d]: property_id %d with value_id %d is not in the listing's inventory", i, vi.PropertyID, vi.ValueID)
+		}
+		if seen[key] {
+			return fmt.Sprintf("variation_images[%d]: property_id %d with value_id %d is mapped more than once", i, vi.PropertyID, vi.ValueID)
+		}
+		seen[key] = true
+		if !images[vi.ImageID] {
+			return fmt.Sprintf("variation_images[%d]: image_id %d is not an image of this listing", i, vi.ImageID)
+		}
+	}
+	return ""
+}
+
 // GET /v3/application/shops/{shop_id}/listings/{listing_id}/properties
 func (h *Handler) GetListingProperties(w http.ResponseWriter, r *http.Request) {
-	_, ok := extractPathID(r.URL.Path, "listings")
+	listingID, ok := extractPathID(r.URL.Path, "listings")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid listing_id")
 		return
 	}
+	if _, found := h.Store.GetListing(listingID); !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+	props := h.Store.GetListingProperties(listingID)
 	writeJSON(w, http.StatusOK, models.PaginatedResponse{
-		Count:   0,
-		Results: []models.ListingPropertyValue{},
+		Count:   len(props),
+		Results: props,
 	})
 }
 
-// GET /v3/application/listings/{listing_id}/products/{product_id}/offerings/{offering_id}
-func (h *Handler) GetListingOffering(w http.ResponseWriter, r *http.Request) {
+// GET /v3/application/listings/{listing_id}/properties/{property_id}
+func (h *Handler) GetListingProperty(w http.ResponseWriter, r *http.Request) {
+	listingID, ok := extractPathID(r.URL.Path, "listings")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid listing_id")
+		return
+	}
+	propertyID, ok := extractPathID(r.URL.Path, "properties")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid property_id")
+		return
+	}
+	if _, found := h.Store.GetListing(listingID); !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+	pv, found := h.Store.GetListingProperty(listingID, propertyID)
+	if !found {
+		writeError(w, http.StatusNotFound, "Property not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, pv)
+}
+
+// PUT /v3/application/shops/{shop_id}/listings/{listing_id}/properties/{property_id}
+func (h *Handler) UpdateListingProperty(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "listings_w") {
+		return
+	}
 	listingID, ok := extractPathID(r.URL.Path, "listings")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid listing_id")
 		return
 	}
-	inv, found := h.Store.GetListingInventory(listingID)
+	propertyID, ok := extractPathID(r.URL.Path, "properties")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid property_id")
+		return
+	}
+	listing, found := h.Store.GetListing(listingID)
 	if !found {
 		writeError(w, http.StatusNotFound, "Listing not found")
 		return
 	}
-	offeringID, ok := extractPathID(r.URL.Path, "offerings")
+
+	var req models.UpdateListingPropertyRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if listing.TaxonomyID == nil {
+		writeError(w, http.StatusBadRequest, "Listing must have a taxonomy_id before properties can be set")
+		return
+	}
+	taxonomyID := int64(*listing.TaxonomyID)
+	props, _ := h.Store.GetTaxonomyProperties(taxonomyID)
+	var prop *models.BuyerTaxonomyNodeProperty
+	for i := range props {
+		if props[i].PropertyID == propertyID {
+			prop = &props[i]
+			break
+		}
+	}
+	if prop == nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("property_id %d is not a valid property for taxonomy_id %d", propertyID, taxonomyID))
+		return
+	}
+	pv, msg := buildListingProperty(prop, &req)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	h.Store.SetListingProperty(listingID, *pv)
+	writeJSON(w, http.StatusOK, pv)
+}
+
+// DELETE /v3/application/shops/{shop_id}/listings/{listing_id}/properties/{property_id}
+func (h *Handler) DeleteListingProperty(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "listings_w") {
+		return
+	}
+	listingID, ok := extractPathID(r.URL.Path, "listings")
 	if !ok {
-		writeError(w, http.StatusBadRequest, "Invalid offering_id")
+		writeError(w, http.StatusBadRequest, "Invalid listing_id")
+		return
+	}
+	propertyID, ok := extractPathID(r.URL.Path, "properties")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid property_id")
+		return
+	}
+	if _, found := h.Store.GetListing(listingID); !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+	if !h.Store.DeleteListingProperty(listingID, propertyID) {
+		writeError(w, http.StatusNotFound, "Property not found")
 		return
 	}
-	for _, p := range inv.Products {
-		for _, o := range p.Offerings {
-			if o.OfferingID == offeringID {
-				writeJSON(w, http.StatusOK, o)
-				return
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// buildListingProperty checks req against the taxonomy property it targets and
+// returns the value to store, or an error message suitable for a 400.
+func buildListingProperty(prop *models.BuyerTaxonomyNodeProperty, req *models.UpdateListingPropertyRequest) (*models.ListingPropertyValue, string) {
+	if !prop.SupportsAttributes {
+		return nil, fmt.Sprintf("Property %d (%s) does not support attributes", prop.PropertyID, prop.DisplayName)
+	}
+	if len(req.ValueIDs) == 0 || len(req.Values) == 0 {
+		return nil, "value_ids and values are required"
+	}
+	if len(req.ValueIDs) != len(req.Values) {
+		return nil, "value_ids and values must have the same number of elements"
+	}
+	if len(req.ValueIDs) > 1 && !prop.IsMultivalued {
+		return nil, fmt.Sprintf("Property %d (%s) accepts only one value", prop.PropertyID, prop.DisplayName)
+	}
+	if prop.MaxValuesAllowed != nil && len(req.ValueIDs) > *prop.MaxValuesAllowed {
+		return nil, fmt.Sprintf("Property %d (%s) accepts at most %d values", prop.PropertyID, prop.DisplayName, *prop.MaxValuesAllowed)
+	}
+
+	var scaleName *string
+	if len(prop.Scales) == 0 {
+		if req.ScaleID != nil {
+			return nil, fmt.Sprintf("Property %d (%s) does not use scales; scale_id must be omitted", prop.PropertyID, prop.DisplayName)
+		}
+	} else {
+		if req.ScaleID == nil {
+			return nil, fmt.Sprintf("scale_id is required for property %d (%s)", prop.PropertyID, prop.DisplayName)
+		}
+		for _, sc := range prop.Scales {
+			if sc.ScaleID == *req.ScaleID {
+				name := sc.DisplayName
+				scaleName = &name
+				break
 			}
 		}
+		if scaleName == nil {
+			return nil, fmt.Sprintf("scale_id %d is not valid for property %d (%s)", *req.ScaleID, prop.PropertyID, prop.DisplayName)
+		}
 	}
-	writeError(w, http.StatusNotFound, "Offering not found")
+
+	possible := make(map[int64]models.BuyerTaxonomyPropertyValue, len(prop.PossibleValues))
+	for _, v := range prop.PossibleValues {
+		possible[v.ValueID] = v
+	}
+	seen := make(map[int]bool, len(req.ValueIDs))
+	for i, id := range req.ValueIDs {
+		if id < 1 {
+			return nil, fmt.Sprintf("value_ids[%d] must be a positive integer", i)
+		}
+		if seen[id] {
+			return nil, fmt.Sprintf("value_id %d is listed more than once", id)
+		}
+		seen[id] = true
+		if strings.ContainsAny(req.Values[i], "()") {
+			return nil, fmt.Sprintf("values[%d] may not contain parentheses", i)
+		}
+		// Properties without possible values accept free-form values.
+		if len(possible) == 0 {
+			continue
+		}
+		v, ok := possible[int64(id)]
+		if !ok {
+			return nil, fmt.Sprintf("value_id %d is not a valid value for property %d (%s)", id, prop.PropertyID, prop.DisplayName)
+		}
+		if v.ScaleID != nil && (req.ScaleID == nil || *v.ScaleID != *req.ScaleID) {
+			return nil, fmt.Sprintf("value_id %d does not belong to the given scale_id", id)
+		}
+	}
+
+	name := prop.DisplayName
+	return &models.ListingPropertyValue{
+		PropertyID:   prop.PropertyID,
+		PropertyName: &name,
+		ScaleID:      req.ScaleID,
+		ScaleName:    scaleName,
+		ValueIDs:     req.ValueIDs,
+		Values:       req.Values,
+	}, ""
+}
+
+// GET /v3/application/listings/{listing_id}/products/{product_id}/offerings/{offering_id}
+func (h *Handler) GetListingOffering(w http.ResponseWriter, r *http.Request) {
+	listingID, ok := extractPathID(r.URL.Path, "listings")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid listing_id")
+		return
+	}
+	productID, ok := extractPathID(r.URL.Path, "products")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid product_id")
+		return
+	}
+	offeringID, ok := extractPathID(r.URL.Path, "offerings")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid offering_id")
+		return
+	}
+	if _, found := h.Store.GetListing(listingID); !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+	o, found := h.Store.GetListingOffering(listingID, productID, offeringID)
+	if !found {
+		writeError(w, http.StatusNotFound, "Offering not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
 }
 
 // GET /v3/application/shops/{shop_id}/production-partners
@@ -412,28 +687,232 @@ func (h *Handler) GetReadinessStateDefinitions(w http.ResponseWriter, r *http.Re
 	if !requireScope(w, r, "shops_r") {
 		return
 	}
-	defs := []models.ReadinessStateDefinition{
-		{ReadinessStateID: 1, Name: "ready_to_ship", Label: "Ready to ship", ProcessingTimeUnit: "business_days", ProcessingMin: 1, ProcessingMax: 3},
-		{ReadinessStateID: 2, Name: "made_to_order", Label: "Made to order", ProcessingTimeUnit: "business_days", ProcessingMin: 3, ProcessingMax: 7},
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	if _, found := h.Store.GetShop(shopID); !found {
+		writeError(w, http.StatusNotFound, "Shop not found")
+		return
+	}
+	limit := queryInt(r, "limit", 25)
+	offset := queryInt(r, "offset", 0)
+	defs, total := h.Store.GetReadinessStateDefinitions(shopID, limit, offset)
+	if defs == nil {
+		defs = []models.ReadinessStateDefinition{}
+	}
+	for i := range defs {
+		defs[i] = readinessStateResponse(defs[i])
 	}
 	writeJSON(w, http.StatusOK, models.PaginatedResponse{
-		Count:   len(defs),
+		Count:   total,
 		Results: defs,
 	})
 }
 
+// GET /v3/application/shops/{shop_id}/readiness-state-definitions/{readiness_state_definition_id}
+func (h *Handler) GetReadinessStateDefinition(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_r") {
+		return
+	}
+	d, ok := h.shopReadinessStateDefinition(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, readinessStateResponse(*d))
+}
+
+// POST /v3/application/shops/{shop_id}/readiness-state-definitions
+func (h *Handler) CreateReadinessStateDefinition(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	if _, found := h.Store.GetShop(shopID); !found {
+		writeError(w, http.StatusNotFound, "Shop not found")
+		return
+	}
+	var body readinessStateBody
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if body.ReadinessState == nil || body.MinProcessingTime == nil || body.MaxProcessingTime == nil {
+		writeError(w, http.StatusBadRequest, "readiness_state, min_processing_time and max_processing_time are required")
+		return
+	}
+	d := &models.ReadinessStateDefinition{ShopID: shopID}
+	if msg := body.apply(d); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if dup, found := h.Store.FindIdenticalReadinessStateDefinition(shopID, 0, d.ReadinessState, d.MinProcessingDays, d.MaxProcessingDays); found {
+		writeError(w, http.StatusConflict, fmt.Sprintf("An identical readiness state definition already exists: %d", dup.ReadinessStateID))
+		return
+	}
+	writeJSON(w, http.StatusCreated, readinessStateResponse(*h.Store.CreateReadinessStateDefinition(d)))
+}
+
+// PUT /v3/application/shops/{shop_id}/readiness-state-definitions/{readiness_state_definition_id}
+func (h *Handler) UpdateReadinessStateDefinition(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	current, ok := h.shopReadinessStateDefinition(w, r)
+	if !ok {
+		return
+	}
+	var body readinessStateBody
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	d := *current
+	if msg := body.apply(&d); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if dup, found := h.Store.FindIdenticalReadinessStateDefinition(d.ShopID, d.ReadinessStateID, d.ReadinessState, d.MinProcessingDays, d.MaxProcessingDays); found {
+		writeError(w, http.StatusConflict, fmt.Sprintf("An identical readiness state definition already exists: %d", dup.ReadinessStateID))
+		return
+	}
+	h.Store.UpdateReadinessStateDefinition(&d)
+	writeJSON(w, http.StatusOK, readinessStateResponse(d))
+}
+
+// DELETE /v3/application/shops/{shop_id}/readiness-state-definitions/{readiness_state_definition_id}
+func (h *Handler) DeleteReadinessStateDefinition(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	d, ok := h.shopReadinessStateDefinition(w, r)
+	if !ok {
+		return
+	}
+	if n := h.Store.CountReadinessStateReferences(d.ReadinessStateID); n > 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Readiness state definition %d is used by %d offerings and cannot be deleted", d.ReadinessStateID, n))
+		return
+	}
+	h.Store.DeleteReadinessStateDefinition(d.ReadinessStateID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// shopReadinessStateDefinition resolves the shop and definition IDs in the
+// path to a definition of that shop. On failure it writes the error response
+// and returns false.
+func (h *Handler) shopReadinessStateDefinition(w http.ResponseWriter, r *http.Request) (*models.ReadinessStateDefinition, bool) {
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return nil, false
+	}
+	id, ok := extractPathID(r.URL.Path, "readiness-state-definitions")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid readiness_state_definition_id")
+		return nil, false
+	}
+	d, found := h.Store.GetReadinessStateDefinition(id)
+	if !found || d.ShopID != shopID {
+		writeError(w, http.StatusNotFound, "Readiness state definition not found")
+		return nil, false
+	}
+	return d, true
+}
+
+// daysPerProcessingWeek is how many days a "weeks" processing time counts
+// for. The spec defines a week as the days the seller works per week in
+// their processing schedule; the mock has no schedules and assumes a
+// five-day working week.
+const daysPerProcessingWeek = 5
+
+type readinessStateBody struct {
+	ReadinessState     *string `json:"readiness_state"`
+	MinProcessingTime  *int    `json:"min_processing_time"`
+	MaxProcessingTime  *int    `json:"max_processing_time"`
+	ProcessingTimeUnit *string `json:"processing_time_unit"`
+}
+
+// apply validates the body and copies the fields it sets onto d. It returns an
+// error message, or "" on success.
+func (b *readinessStateBody) apply(d *models.ReadinessStateDefinition) string {
+	if b.ReadinessState != nil {
+		if *b.ReadinessState != "ready_to_ship" && *b.ReadinessState != "made_to_order" {
+			return "readiness_state must be one of ready_to_ship, made_to_order"
+		}
+		d.ReadinessState = *b.ReadinessState
+	}
+	// Times left out of the body keep their stored value, read back in the
+	// unit the definition was stored with.
+	storedUnit := d.ProcessingTimeUnit
+	if storedUnit == "" {
+		storedUnit = "days"
+	}
+	minTime, maxTime := d.MinProcessingDays, d.MaxProcessingDays
+	if storedUnit == "weeks" {
+		minTime /= daysPerProcessingWeek
+		maxTime /= daysPerProcessingWeek
+	}
+	unit := storedUnit
+	if b.ProcessingTimeUnit != nil {
+		if *b.ProcessingTimeUnit != "days" && *b.ProcessingTimeUnit != "weeks" {
+			return "processing_time_unit must be one of days, weeks"
+		}
+		unit = *b.ProcessingTimeUnit
+	}
+	if b.MinProcessingTime != nil {
+		minTime = *b.MinProcessingTime
+	}
+	if b.MaxProcessingTime != nil {
+		maxTime = *b.MaxProcessingTime
+	}
+	if minTime < 1 || minTime > 10 || maxTime < 1 || maxTime > 10 {
+		return "min_processing_time and max_processing_time must be between 1 and 10"
+	}
+	if minTime > maxTime {
+		return "min_processing_time cannot be greater than max_processing_time"
+	}
+
+	if minTime == maxTime {
+		d.ProcessingDaysDisplayLabel = fmt.Sprintf("%d %s", minTime, unit)
+	} else {
+		d.ProcessingDaysDisplayLabel = fmt.Sprintf("%d-%d %s", minTime, maxTime, unit)
+	}
+	if unit == "weeks" {
+		minTime *= daysPerProcessingWeek
+		maxTime *= daysPerProcessingWeek
+	}
+	d.MinProcessingDays = minTime
+	d.MaxProcessingDays = maxTime
+	d.ProcessingTimeUnit = unit
+	return ""
+}
+
+// readinessStateResponse is d as Etsy returns it, without the unit the mock
+// keeps for itself.
+func readinessStateResponse(d models.ReadinessStateDefinition) models.ReadinessStateDefinition {
+	d.ProcessingTimeUnit = ""
+	return d
+}
+
+// shippingCarriers are the carriers and mail classes the mock supports.
+var shippingCarriers = []models.ShippingCarrier{
+	{ShippingCarrierID: 1, Name: "USPS", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "usps_first_class", Name: "First Class"}, {MailClassKey: "usps_priority", Name: "Priority Mail"}, {MailClassKey: "usps_priority_express", Name: "Priority Mail Express"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "usps_first_class_international", Name: "First Class International"}, {MailClassKey: "usps_priority_international", Name: "Priority Mail International"}}},
+	{ShippingCarrierID: 2, Name: "UPS", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "ups_ground", Name: "Ground"}, {MailClassKey: "ups_2day", Name: "2nd Day Air"}, {MailClassKey: "ups_next_day", Name: "Next Day Air"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "ups_worldwide_express", Name: "Worldwide Express"}}},
+	{ShippingCarrierID: 3, Name: "FedEx", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "fedex_ground", Name: "Ground"}, {MailClassKey: "fedex_2day", Name: "2Day"}, {MailClassKey: "fedex_overnight", Name: "Standard Overnight"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "fedex_international_economy", Name: "International Economy"}}},
+	{ShippingCarrierID: 4, Name: "Canada Post", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "canadapost_regular", Name: "Regular Parcel"}, {MailClassKey: "canadapost_expedited", Name: "Expedited Parcel"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "canadapost_international", Name: "International Parcel"}}},
+	{ShippingCarrierID: 5, Name: "Royal Mail", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "royalmail_first", Name: "1st Class"}, {MailClassKey: "royalmail_second", Name: "2nd Class"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "royalmail_international_standard", Name: "International Standard"}}},
+}
+
 // GET /v3/application/shipping-carriers
 func (h *Handler) GetShippingCarriers(w http.ResponseWriter, r *http.Request) {
-	carriers := []models.ShippingCarrier{
-		{ShippingCarrierID: 1, Name: "USPS", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "usps_first_class", Name: "First Class"}, {MailClassKey: "usps_priority", Name: "Priority Mail"}, {MailClassKey: "usps_priority_express", Name: "Priority Mail Express"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "usps_first_class_international", Name: "First Class International"}, {MailClassKey: "usps_priority_international", Name: "Priority Mail International"}}},
-		{ShippingCarrierID: 2, Name: "UPS", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "ups_ground", Name: "Ground"}, {MailClassKey: "ups_2day", Name: "2nd Day Air"}, {MailClassKey: "ups_next_day", Name: "Next Day Air"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "ups_worldwide_express", Name: "Worldwide Express"}}},
-		{ShippingCarrierID: 3, Name: "FedEx", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "fedex_ground", Name: "Ground"}, {MailClassKey: "fedex_2day", Name: "2Day"}, {MailClassKey: "fedex_overnight", Name: "Standard Overnight"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "fedex_international_economy", Name: "International Economy"}}},
-		{ShippingCarrierID: 4, Name: "Canada Post", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "canadapost_regular", Name: "Regular Parcel"}, {MailClassKey: "canadapost_expedited", Name: "Expedited Parcel"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "canadapost_international", Name: "International Parcel"}}},
-		{ShippingCarrierID: 5, Name: "Royal Mail", DomesticClasses: []models.ShippingCarrierMailClass{{MailClassKey: "royalmail_first", Name: "1st Class"}, {MailClassKey: "royalmail_second", Name: "2nd Class"}}, InternationalClasses: []models.ShippingCarrierMailClass{{MailClassKey: "royalmail_international_standard", Name: "International Standard"}}},
-	}
 	writeJSON(w, http.StatusOK, models.PaginatedResponse{
-		Count:   len(carriers),
-		Results: carriers,
+		Count:   len(shippingCarriers),
+		Results: shippingCarriers,
 	})
 }
 
@@ -518,7 +997,7 @@ func (h *Handler) CreateReceiptTracking(w http.ResponseWriter, r *http.Request)
 	shipID := h.Store.NextID()
 	receipt.Shipments = append(receipt.Shipments, models.ShopReceiptShipment{
 		ReceiptShippingID:             &shipID,
-		ShipmentNotificationTimestamp: time.Now().Unix(),
+		ShipmentNotificationTimestamp: clock.Now().Unix(),
 		CarrierName:                   body.CarrierName,
 		TrackingCode:                  body.TrackingCode,
 	})
@@ -557,25 +1036,24 @@ func (h *Handler) GetShopSectionListings(w http.ResponseWriter, r *http.Request)
 	}
 	limit := queryInt(r, "limit", 25)
 	offset := queryInt(r, "offset", 0)
-	sectionIDs := r.URL.Query().Get("shop_section_ids")
+	sortOn := queryString(r, "sort_on", "created")
+	sortOrder := queryString(r, "sort_order", "desc")
 
-	listings, total := h.Store.GetShopListings(shopID, "active", limit, offset)
-	if sectionIDs != "" {
-		var filtered []models.ShopListing
-		for _, idStr := range splitCSV(sectionIDs) {
-			secID, ok := parseID(idStr)
-			if !ok {
-				continue
-			}
-			for _, l := range listings {
-				if l.ShopSectionID != nil && *l.ShopSectionID == secID {
-					filtered = append(filtered, l)
-				}
-			}
+	var sectionIDs []int64
+	for _, idStr := range splitCSV(r.URL.Query().Get("shop_section_ids")) {
+		secID, ok := parseID(idStr)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Invalid shop_section_ids")
+			return
 		}
-		listings = filtered
-		total = len(filtered)
+		sectionIDs = append(sectionIDs, secID)
 	}
+	if len(sectionIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "shop_section_ids is required")
+		return
+	}
+
+	listings, total := h.Store.GetListingsByShopSections(shopID, sectionIDs, limit, offset, sortOn, sortOrder)
 	if listings == nil {
 		listings = []models.ShopListing{}
 	}
@@ -614,14 +1092,6 @@ func (h *Handler) GetSellerTaxonomyProperties(w http.ResponseWriter, r *http.Req
 	})
 }
 
-// DELETE /v3/application/users/{user_id}/addresses/{user_address_id}
-func (h *Handler) DeleteUserAddress(w http.ResponseWriter, r *http.Request) {
-	if !requireScope(w, r, "address_r") {
-		return
-	}
-	w.WriteHeader(http.StatusNoContent)
-}
-
 // Helper: extract a string path segment after a given key
 func extractPathSegment(path string, after string) string {
 	parts := splitPath(path)
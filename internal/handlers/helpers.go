@@ -8,16 +8,27 @@ import (
 
 	"github.com/vlah-software-house/etsy-mock-api/internal/middleware"
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
+	"github.com/vlah-software-house/etsy-mock-api/internal/openapi"
 	"github.com/vlah-software-house/etsy-mock-api/internal/store"
 )
 
 type Handler struct {
 	Store      *store.Store
 	TokenStore *middleware.TokenStore
+	KeyStore   *middleware.APIKeyStore
+	// Seed populates a fresh store the same way the server did at startup.
+	// Nil means the server was started with -no-seed.
+	Seed      func(*store.Store)
+	Snapshots *store.Snapshots
+	Faults    *middleware.FaultStore
+	// Spec and Hits back /admin/coverage. Both are nil when no OpenAPI
+	// document was loaded.
+	Spec *openapi.Spec
+	Hits *openapi.Hits
 }
 
-func New(s *store.Store, ts *middleware.TokenStore) *Handler {
-	return &Handler{Store: s, TokenStore: ts}
+func New(s *store.Store, ts *middleware.TokenStore, ks *middleware.APIKeyStore, seed func(*store.Store)) *Handler {
+	return &Handler{Store: s, TokenStore: ts, KeyStore: ks, Seed: seed, Snapshots: store.NewSnapshots(), Faults: middleware.NewFaultStore()}
 }
 
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
@@ -47,6 +58,9 @@ func parseID(s string) (int64, bool) {
 	return id, err == nil
 }
 
+// queryInt returns the integer query parameter key, or def when it is absent
+// or malformed. Only a server started with -spec rejects malformed values,
+// with a 400 from middleware.ValidateRequests before a handler runs.
 func queryInt(r *http.Request, key string, def int) int {
 	s := r.URL.Query().Get(key)
 	if s == "" {
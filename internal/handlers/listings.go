@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 )
@@ -30,6 +33,12 @@ func (h *Handler) CreateListing(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Missing required fields: title, quantity, price, who_made, when_made, taxonomy_id")
 		return
 	}
+	if req.ShopSectionID != nil {
+		if _, found := h.Store.GetShopSection(shopID, *req.ShopSectionID); !found {
+			writeError(w, http.StatusBadRequest, "shop_section_id does not belong to this shop")
+			return
+		}
+	}
 
 	listing := h.Store.CreateListing(shopID, req)
 	writeJSON(w, http.StatusCreated, listing)
@@ -114,6 +123,14 @@ func (h *Handler) UpdateListing(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	if req.ShopSectionID != nil && *req.ShopSectionID != 0 {
+		if current, found := h.Store.GetListing(listingID); found {
+			if _, found := h.Store.GetShopSection(current.ShopID, *req.ShopSectionID); !found {
+				writeError(w, http.StatusBadRequest, "shop_section_id does not belong to this shop")
+				return
+			}
+		}
+	}
 
 	listing, found := h.Store.UpdateListing(listingID, req)
 	if !found {
@@ -328,3 +345,237 @@ func (h *Handler) GetListingInventory(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, inv)
 }
+
+// GET /v3/application/listings/{listing_id}/inventory/products/{product_id}
+func (h *Handler) GetListingProduct(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "listings_r") {
+		return
+	}
+	listingID, ok := extractPathID(r.URL.Path, "listings")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid listing_id")
+		return
+	}
+	productID, ok := extractPathID(r.URL.Path, "products")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid product_id")
+		return
+	}
+	if _, found := h.Store.GetListing(listingID); !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+	p, found := h.Store.GetListingProduct(listingID, productID)
+	if !found {
+		writeError(w, http.StatusNotFound, "Product not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// PUT /v3/application/listings/{listing_id}/inventory
+func (h *Handler) UpdateListingInventory(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "listings_w") {
+		return
+	}
+	listingID, ok := extractPathID(r.URL.Path, "listings")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid listing_id")
+		return
+	}
+	listing, found := h.Store.GetListing(listingID)
+	if !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+
+	var req models.UpdateListingInventoryRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := validateInventory(&req); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	for i, p := range req.Products {
+		for _, o := range p.Offerings {
+			if o.ReadinessStateID == nil {
+				continue
+			}
+			if d, found := h.Store.GetReadinessStateDefinition(*o.ReadinessStateID); !found || d.ShopID != listing.ShopID {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("products[%d] readiness_state_id %d is not a readiness state definition of this shop", i, *o.ReadinessStateID))
+				return
+			}
+		}
+	}
+
+	currency := listing.Price.CurrencyCode
+	if currency == "" {
+		currency = "USD"
+	}
+	inv := models.ListingInventory{
+		Products:                 make([]models.ListingInventoryProduct, len(req.Products)),
+		PriceOnProperty:          nonNilInts(req.PriceOnProperty),
+		QuantityOnProperty:       nonNilInts(req.QuantityOnProperty),
+		SKUOnProperty:            nonNilInts(req.SKUOnProperty),
+		ReadinessStateOnProperty: nonNilInts(req.ReadinessStateOnProperty),
+	}
+	for i, p := range req.Products {
+		product := models.ListingInventoryProduct{
+			PropertyValues: p.PropertyValues,
+			Offerings:      make([]models.ListingInventoryProductOffering, len(p.Offerings)),
+		}
+		if p.SKU != nil {
+			product.SKU = *p.SKU
+		}
+		if product.PropertyValues == nil {
+			product.PropertyValues = []models.ListingPropertyValue{}
+		}
+		for j, o := range p.Offerings {
+			product.Offerings[j] = models.ListingInventoryProductOffering{
+				Quantity:         o.Quantity,
+				IsEnabled:        o.IsEnabled,
+				Price:            models.NewMoney(o.Price, currency),
+				ReadinessStateID: o.ReadinessStateID,
+			}
+		}
+		inv.Products[i] = product
+	}
+
+	updated, found := h.Store.UpdateListingInventory(listingID, inv)
+	if !found {
+		writeError(w, http.StatusNotFound, "Listing not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// validateInventory applies Etsy's updateListingInventory rules and returns
+// an error message, or "" if the request is acceptable.
+func validateInventory(req *models.UpdateListingInventoryRequest) string {
+	if len(req.Products) == 0 {
+		return "products must contain at least one product"
+	}
+
+	var propertyIDs map[int64]bool
+	combinations := make(map[string]bool)
+	for i, p := range req.Products {
+		if len(p.Offerings) != 1 {
+			return fmt.Sprintf("products[%d] must have exactly one offering", i)
+		}
+		o := p.Offerings[0]
+		if o.Price < 0.2 || o.Price > 50000 {
+			return fmt.Sprintf("products[%d] offering price must be between 0.20 and 50000.00", i)
+		}
+		if o.Quantity < 0 || o.Quantity > 999 {
+			return fmt.Sprintf("products[%d] offering quantity must be between 0 and 999", i)
+		}
+		if len(p.PropertyValues) > 2 {
+			return fmt.Sprintf("products[%d] may vary on at most 2 properties", i)
+		}
+
+		ids := make(map[int64]bool)
+		for _, pv := range p.PropertyValues {
+			if pv.PropertyID < 1 {
+				return fmt.Sprintf("products[%d] has an invalid property_id", i)
+			}
+			if ids[pv.PropertyID] {
+				return fmt.Sprintf("products[%d] lists property %d more than once", i, pv.PropertyID)
+			}
+			ids[pv.PropertyID] = true
+			if len(pv.ValueIDs) != 1 || len(pv.Values) != 1 {
+				return fmt.Sprintf("products[%d] property %d must have exactly one value_id and one value", i, pv.PropertyID)
+			}
+			if strings.ContainsAny(pv.Values[0], "()") {
+				return fmt.Sprintf("products[%d] property %d value may not contain parentheses", i, pv.PropertyID)
+			}
+		}
+		if i == 0 {
+			propertyIDs = ids
+		} else if !sameIDSet(ids, propertyIDs) {
+			return fmt.Sprintf("products[%d] must use the same properties as every other product", i)
+		}
+
+		combination := models.ListingInventoryProduct{PropertyValues: p.PropertyValues}.Combination()
+		if combinations[combination] {
+			return fmt.Sprintf("products[%d] duplicates the property values of another product", i)
+		}
+		combinations[combination] = true
+	}
+
+	onProperty := []struct {
+		field string
+		ids   []int
+		value func(p models.UpdateListingInventoryProduct) string
+	}{
+		{"price_on_property", req.PriceOnProperty, func(p models.UpdateListingInventoryProduct) string {
+			return fmt.Sprintf("%.2f", p.Offerings[0].Price)
+		}},
+		{"quantity_on_property", req.QuantityOnProperty, func(p models.UpdateListingInventoryProduct) string {
+			return fmt.Sprint(p.Offerings[0].Quantity)
+		}},
+		{"sku_on_property", req.SKUOnProperty, func(p models.UpdateListingInventoryProduct) string {
+			if p.SKU == nil {
+				return ""
+			}
+			return *p.SKU
+		}},
+		{"readiness_state_on_property", req.ReadinessStateOnProperty, func(p models.UpdateListingInventoryProduct) string {
+			if p.Offerings[0].ReadinessStateID == nil {
+				return ""
+			}
+			return fmt.Sprint(*p.Offerings[0].ReadinessStateID)
+		}},
+	}
+	for _, op := range onProperty {
+		listed := make(map[int64]bool)
+		for _, id := range op.ids {
+			if !propertyIDs[int64(id)] {
+				return fmt.Sprintf("%s contains property %d, which is not in the products' property_values", op.field, id)
+			}
+			if listed[int64(id)] {
+				return fmt.Sprintf("%s lists property %d more than once", op.field, id)
+			}
+			listed[int64(id)] = true
+		}
+
+		// Products that agree on every listed property must agree on the value.
+		values := make(map[string]string)
+		for _, p := range req.Products {
+			var key []string
+			for _, pv := range p.PropertyValues {
+				if listed[pv.PropertyID] {
+					key = append(key, fmt.Sprintf("%d:%v", pv.PropertyID, pv.ValueIDs))
+				}
+			}
+			sort.Strings(key)
+			k := strings.Join(key, ",")
+			v := op.value(p)
+			if prev, ok := values[k]; ok && prev != v {
+				return fmt.Sprintf("Products differ on a value that does not vary by %s", op.field)
+			}
+			values[k] = v
+		}
+	}
+	return ""
+}
+
+func sameIDSet(a, b map[int64]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id := range a {
+		if !b[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func nonNilInts(v []int) []int {
+	if v == nil {
+		return []int{}
+	}
+	return v
+}
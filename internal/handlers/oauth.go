@@ -8,7 +8,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
 	"github.com/vlah-software-house/etsy-mock-api/internal/middleware"
+	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 )
 
 // GET /v3/application/openapi-ping
@@ -36,18 +38,24 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusForbidden, "This endpoint requires OAuth2. Provide a Bearer token.")
 		return
 	}
-	user, found := h.Store.GetUser(userID)
-	if !found {
+	if !requireScope(w, r, "shops_r") {
+		return
+	}
+	if _, found := h.Store.GetUser(userID); !found {
 		writeError(w, http.StatusNotFound, "User not found")
 		return
 	}
-	writeJSON(w, http.StatusOK, user)
+	self := models.Self{UserID: userID}
+	if shop, found := h.Store.GetShopByOwner(userID); found {
+		self.ShopID = &shop.ShopID
+	}
+	writeJSON(w, http.StatusOK, self)
 }
 
 // POST /v3/public/oauth/token — Token exchange and refresh
 func (h *Handler) OAuthToken(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid form data")
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid form data")
 		return
 	}
 
@@ -55,12 +63,27 @@ func (h *Handler) OAuthToken(w http.ResponseWriter, r *http.Request) {
 	clientID := r.FormValue("client_id")
 
 	switch grantType {
-	case "authorization_code":
+	case "authorization_code", "refresh_token":
+	case "":
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "grant_type is required")
+		return
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", fmt.Sprintf("Unsupported grant_type: %s", grantType))
+		return
+	}
+	if clientID == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "client_id is required")
+		return
+	}
+	if entry, ok := h.KeyStore.Get(clientID); !ok || entry.Status != middleware.APIKeyValid {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "client_id is not a valid API keystring")
+		return
+	}
+
+	if grantType == "authorization_code" {
 		h.handleAuthCodeExchange(w, r, clientID)
-	case "refresh_token":
+	} else {
 		h.handleRefreshToken(w, r, clientID)
-	default:
-		writeError(w, http.StatusBadRequest, fmt.Sprintf("Unsupported grant_type: %s", grantType))
 	}
 }
 
@@ -68,77 +91,82 @@ func (h *Handler) handleAuthCodeExchange(w http.ResponseWriter, r *http.Request,
 	code := r.FormValue("code")
 	codeVerifier := r.FormValue("code_verifier")
 
-	if clientID == "" || code == "" || codeVerifier == "" {
-		writeError(w, http.StatusBadRequest, "client_id, code, and code_verifier are required")
+	if code == "" || codeVerifier == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "code and code_verifier are required")
 		return
 	}
 
-	// In mock mode, accept any code and verifier. Extract user from the code pattern.
-	// Convention: code format is "mock_code_{user_id}_{scopes}" or just any string.
-	// Default to user 1001 if we can't parse.
-	userID := int64(1001)
-	scopes := middleware.AllScopes()
-
-	// Try to extract user ID from code like "mock_code_1001"
-	if strings.HasPrefix(code, "mock_code_") {
-		rest := strings.TrimPrefix(code, "mock_code_")
-		parts := strings.SplitN(rest, "_", 2)
-		if id, ok := parseID(parts[0]); ok {
-			userID = id
-		}
+	// Codes come from /oauth/connect and are consumed here even if the
+	// exchange fails, so each can only be tried once.
+	authCode, ok := h.TokenStore.TakeCode(code)
+	if !ok {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Invalid or expired authorization code")
+		return
+	}
+	if authCode.ClientID != clientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "client_id does not match the authorization code")
+		return
+	}
+	if authCode.RedirectURI != r.FormValue("redirect_uri") {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match the authorization request")
+		return
+	}
+	if !pkceMatches(codeVerifier, authCode.CodeChallenge) {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "code_verifier does not match the code_challenge")
+		return
 	}
 
-	accessToken := generateToken(userID)
-	refreshToken := "refresh_" + generateToken(userID)
-
-	h.TokenStore.Store(&middleware.TokenEntry{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		UserID:       userID,
-		Scopes:       scopes,
-		ExpiresAt:    time.Now().Add(1 * time.Hour),
-	})
-
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"access_token":  accessToken,
-		"token_type":    "Bearer",
-		"expires_in":    3600,
-		"refresh_token": refreshToken,
-	})
+	h.issueToken(w, clientID, authCode.UserID, strings.Fields(authCode.Scope))
 }
 
 func (h *Handler) handleRefreshToken(w http.ResponseWriter, r *http.Request, clientID string) {
 	refreshToken := r.FormValue("refresh_token")
-	if clientID == "" || refreshToken == "" {
-		writeError(w, http.StatusBadRequest, "client_id and refresh_token are required")
+	if refreshToken == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "refresh_token is required")
 		return
 	}
 
 	entry, ok := h.TokenStore.GetByRefresh(refreshToken)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "Invalid refresh token")
+		return
+	}
+	if entry.ClientID != "" && entry.ClientID != clientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "refresh_token was issued to another client")
 		return
 	}
 
-	newAccessToken := generateToken(entry.UserID)
-	newRefreshToken := "refresh_" + generateToken(entry.UserID)
+	h.issueToken(w, clientID, entry.UserID, entry.Scopes)
+}
+
+// issueToken mints an access and refresh token pair limited to scopes.
+func (h *Handler) issueToken(w http.ResponseWriter, clientID string, userID int64, scopes []string) {
+	accessToken := generateToken(userID)
+	refreshToken := "refresh_" + generateToken(userID)
 
 	h.TokenStore.Store(&middleware.TokenEntry{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
-		UserID:       entry.UserID,
-		Scopes:       entry.Scopes,
-		ExpiresAt:    time.Now().Add(1 * time.Hour),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ClientID:     clientID,
+		UserID:       userID,
+		Scopes:       scopes,
+		ExpiresAt:    clock.Now().Add(1 * time.Hour),
 	})
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"access_token":  newAccessToken,
+		"access_token":  accessToken,
 		"token_type":    "Bearer",
 		"expires_in":    3600,
-		"refresh_token": newRefreshToken,
+		"refresh_token": refreshToken,
 	})
 }
 
+// writeOAuthError writes an RFC 6749 error: one of the spec's error codes
+// plus a human-readable description.
+func writeOAuthError(w http.ResponseWriter, status int, code, desc string) {
+	writeJSON(w, status, models.OAuthErrorResponse{Error: code, ErrorDescription: desc})
+}
+
 func generateToken(userID int64) string {
 	b := make([]byte, 16)
 	rand.Read(b)
@@ -11,12 +11,11 @@ func (h *Handler) GetReceiptPayments(w http.ResponseWriter, r *http.Request) {
 	if !requireScope(w, r, "transactions_r") {
 		return
 	}
-	receiptID, ok := extractPathID(r.URL.Path, "receipts")
+	receipt, ok := h.shopReceipt(w, r)
 	if !ok {
-		writeError(w, http.StatusBadRequest, "Invalid receipt_id")
 		return
 	}
-	payments := h.Store.GetPaymentsByReceipt(receiptID)
+	payments := h.Store.GetPaymentsByReceipt(receipt.ReceiptID)
 	if payments == nil {
 		payments = []models.Payment{}
 	}
@@ -36,7 +35,21 @@ func (h *Handler) GetShopPayments(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Invalid shop_id")
 		return
 	}
-	payments := h.Store.GetPaymentsByShop(shopID)
+	var payments []models.Payment
+	if r.URL.Query().Get("payment_ids") != "" {
+		var paymentIDs []int64
+		for _, idStr := range splitCSV(r.URL.Query().Get("payment_ids")) {
+			id, ok := parseID(idStr)
+			if !ok {
+				writeError(w, http.StatusBadRequest, "Invalid payment_ids")
+				return
+			}
+			paymentIDs = append(paymentIDs, id)
+		}
+		payments = h.Store.GetPayments(shopID, paymentIDs)
+	} else {
+		payments = h.Store.GetPaymentsByShop(shopID)
+	}
 	if payments == nil {
 		payments = []models.Payment{}
 	}
@@ -56,10 +69,24 @@ func (h *Handler) GetLedgerEntries(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Invalid shop_id")
 		return
 	}
+	minCreated, ok := parseID(r.URL.Query().Get("min_created"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "min_created is required and must be a unix timestamp")
+		return
+	}
+	maxCreated, ok := parseID(r.URL.Query().Get("max_created"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "max_created is required and must be a unix timestamp")
+		return
+	}
+	if minCreated > maxCreated {
+		writeError(w, http.StatusBadRequest, "min_created cannot be after max_created")
+		return
+	}
 	limit := queryInt(r, "limit", 25)
 	offset := queryInt(r, "offset", 0)
 
-	entries, total := h.Store.GetLedgerEntries(shopID, limit, offset)
+	entries, total := h.Store.GetLedgerEntries(shopID, minCreated, maxCreated, limit, offset)
 	if entries == nil {
 		entries = []models.PaymentAccountLedgerEntry{}
 	}
@@ -68,3 +95,59 @@ func (h *Handler) GetLedgerEntries(w http.ResponseWriter, r *http.Request) {
 		Results: entries,
 	})
 }
+
+// GET /v3/application/shops/{shop_id}/payment-account/ledger-entries/{ledger_entry_id}
+func (h *Handler) GetLedgerEntry(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "transactions_r") {
+		return
+	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	entryID, ok := extractPathID(r.URL.Path, "ledger-entries")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid ledger_entry_id")
+		return
+	}
+	entry, found := h.Store.GetLedgerEntry(shopID, entryID)
+	if !found {
+		writeError(w, http.StatusNotFound, "Ledger entry not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+// GET /v3/application/shops/{shop_id}/payment-account/ledger-entries/payments
+func (h *Handler) GetLedgerEntryPayments(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "transactions_r") {
+		return
+	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	var entryIDs []int64
+	for _, idStr := range splitCSV(r.URL.Query().Get("ledger_entry_ids")) {
+		id, ok := parseID(idStr)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Invalid ledger_entry_ids")
+			return
+		}
+		entryIDs = append(entryIDs, id)
+	}
+	if len(entryIDs) == 0 {
+		writeError(w, http.StatusBadRequest, "ledger_entry_ids is required")
+		return
+	}
+	payments := h.Store.GetLedgerEntryPayments(shopID, entryIDs)
+	if payments == nil {
+		payments = []models.Payment{}
+	}
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   len(payments),
+		Results: payments,
+	})
+}
@@ -111,19 +111,110 @@ func (h *Handler) GetTransaction(w http.ResponseWriter, r *http.Request) {
 	if !requireScope(w, r, "transactions_r") {
 		return
 	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
 	txnID, ok := extractPathID(r.URL.Path, "transactions")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid transaction_id")
 		return
 	}
-	txn, found := h.Store.GetTransaction(txnID)
+	shop, found := h.Store.GetShop(shopID)
 	if !found {
+		writeError(w, http.StatusNotFound, "Shop not found")
+		return
+	}
+	txn, found := h.Store.GetTransaction(txnID)
+	if !found || txn.SellerUserID != shop.UserID {
 		writeError(w, http.StatusNotFound, "Transaction not found")
 		return
 	}
 	writeJSON(w, http.StatusOK, txn)
 }
 
+// GET /v3/application/shops/{shop_id}/listings/{listing_id}/transactions
+func (h *Handler) GetListingTransactions(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "transactions_r") {
+		return
+	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	listingID, ok := extractPathID(r.URL.Path, "listings")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid listing_id")
+		return
+	}
+	if _, found := h.Store.GetShop(shopID); !found {
+		writeError(w, http.StatusNotFound, "Shop not found")
+		return
+	}
+	limit := queryInt(r, "limit", 25)
+	offset := queryInt(r, "offset", 0)
+
+	txns, total := h.Store.GetListingTransactions(shopID, listingID, limit, offset)
+	if txns == nil {
+		txns = []models.ShopReceiptTransaction{}
+	}
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   total,
+		Results: txns,
+	})
+}
+
+// GET /v3/application/shops/{shop_id}/receipts/{receipt_id}/listings
+func (h *Handler) GetReceiptListings(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "transactions_r") {
+		return
+	}
+	receipt, ok := h.shopReceipt(w, r)
+	if !ok {
+		return
+	}
+	limit := queryInt(r, "limit", 25)
+	offset := queryInt(r, "offset", 0)
+
+	listings, total := h.Store.GetReceiptListings(receipt.ReceiptID, limit, offset)
+	if listings == nil {
+		listings = []models.ShopListing{}
+	}
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   total,
+		Results: listings,
+	})
+}
+
+// shopReceipt resolves the shop and receipt IDs in the path to a receipt
+// sold by that shop. On failure it writes the error response and returns
+// false.
+func (h *Handler) shopReceipt(w http.ResponseWriter, r *http.Request) (*models.ShopReceipt, bool) {
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return nil, false
+	}
+	receiptID, ok := extractPathID(r.URL.Path, "receipts")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid receipt_id")
+		return nil, false
+	}
+	shop, found := h.Store.GetShop(shopID)
+	if !found {
+		writeError(w, http.StatusNotFound, "Shop not found")
+		return nil, false
+	}
+	receipt, found := h.Store.GetReceipt(receiptID)
+	if !found || receipt.SellerUserID != shop.UserID {
+		writeError(w, http.StatusNotFound, "Receipt not found")
+		return nil, false
+	}
+	return receipt, true
+}
+
 // GET /v3/application/shops/{shop_id}/receipts/{receipt_id}/transactions
 func (h *Handler) GetReceiptTransactions(w http.ResponseWriter, r *http.Request) {
 	if !requireScope(w, r, "transactions_r") {
@@ -11,19 +11,30 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	// OAuth2 public endpoints (no auth required)
 	mux.HandleFunc("/v3/public/oauth/token", h.OAuthToken)
 
+	// OAuth2 browser consent page that issues authorization codes
+	mux.HandleFunc("/oauth/connect", h.OAuthConnect)
+
 	// Health check / ping (no auth needed)
 	mux.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	})
 
 	// Admin endpoint to reset data
-	mux.HandleFunc("/admin/reset", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			writeError(w, http.StatusMethodNotAllowed, "POST only")
-			return
-		}
-		writeJSON(w, http.StatusOK, map[string]string{"status": "reset"})
-	})
+	mux.HandleFunc("/admin/reset", h.AdminReset)
+
+	// Admin endpoints for named store snapshots
+	mux.HandleFunc("/admin/snapshots", h.routeAdminSnapshots)
+	mux.HandleFunc("/admin/snapshots/", h.routeAdminSnapshots)
+
+	// Admin endpoints for fault injection rules
+	mux.HandleFunc("/admin/faults", h.routeAdminFaults)
+	mux.HandleFunc("/admin/faults/", h.routeAdminFaults)
+
+	// Admin endpoint for the mock's clock
+	mux.HandleFunc("/admin/clock", h.routeAdminClock)
+
+	// Admin endpoint reporting which spec operations are served and called
+	mux.HandleFunc("/admin/coverage", h.AdminCoverage)
 }
 
 func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
@@ -94,6 +105,10 @@ func (h *Handler) route(w http.ResponseWriter, r *http.Request) {
 		h.routeUsers(w, r)
 		return
 	}
+	if path == "/v3/application/user/addresses" || strings.HasPrefix(path, "/v3/application/user/addresses/") {
+		h.routeUserAddresses(w, r, strings.Split(strings.TrimPrefix(path, "/v3/application/user/"), "/"))
+		return
+	}
 
 	// Shop routes
 	if strings.HasPrefix(path, "/v3/application/shops") {
@@ -112,7 +127,26 @@ func (h *Handler) routeListings(w http.ResponseWriter, r *http.Request) {
 	if len(parts) >= 2 {
 		switch parts[1] {
 		case "inventory":
-			h.GetListingInventory(w, r)
+			// /listings/{id}/inventory/products/{pid}
+			if len(parts) == 4 && parts[2] == "products" {
+				if r.Method == http.MethodGet {
+					h.GetListingProduct(w, r)
+					return
+				}
+				writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+				return
+			}
+			if len(parts) > 2 {
+				break
+			}
+			switch r.Method {
+			case http.MethodGet:
+				h.GetListingInventory(w, r)
+			case http.MethodPut:
+				h.UpdateListingInventory(w, r)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			}
 			return
 		case "reviews":
 			h.GetListingReviews(w, r)
@@ -130,9 +164,15 @@ func (h *Handler) routeListings(w http.ResponseWriter, r *http.Request) {
 		case "images":
 			h.GetListingImages(w, r)
 			return
+		case "properties":
+			// /listings/{id}/properties/{pid}
+			if len(parts) == 3 {
+				h.GetListingProperty(w, r)
+				return
+			}
 		case "products":
 			// /listings/{id}/products/{pid}/offerings/{oid}
-			if len(parts) >= 4 && parts[3] == "offerings" {
+			if len(parts) == 5 && parts[3] == "offerings" {
 				h.GetListingOffering(w, r)
 				return
 			}
@@ -163,14 +203,10 @@ func (h *Handler) routeUsers(w http.ResponseWriter, r *http.Request) {
 	if len(parts) >= 2 {
 		switch parts[1] {
 		case "addresses":
-			if len(parts) == 3 && r.Method == http.MethodDelete {
-				h.DeleteUserAddress(w, r)
-				return
-			}
-			h.GetUserAddresses(w, r)
+			h.routeUserAddresses(w, r, parts[1:])
 			return
 		case "shops":
-			h.GetUserShops(w, r)
+			h.GetShopByOwnerUserID(w, r)
 			return
 		}
 	}
@@ -178,6 +214,31 @@ func (h *Handler) routeUsers(w http.ResponseWriter, r *http.Request) {
 	h.GetUser(w, r)
 }
 
+// routeUserAddresses handles addresses[/{user_address_id}] under either
+// /user or /users/{user_id}.
+func (h *Handler) routeUserAddresses(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) == 1 {
+		if r.Method == http.MethodGet {
+			h.GetUserAddresses(w, r)
+			return
+		}
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetUserAddress(w, r)
+		case http.MethodDelete:
+			h.DeleteUserAddress(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, "Endpoint not found")
+}
+
 func (h *Handler) routeShops(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 
@@ -235,13 +296,29 @@ func (h *Handler) routeShops(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		writeError(w, http.StatusNotFound, "Endpoint not found")
+	case "policies":
+		// /shops/{id}/policies/return/... is the spec's path for return-policies
+		if len(parts) >= 3 && parts[2] == "return" {
+			h.routeReturnPolicies(w, r, append([]string{parts[0], "return"}, parts[3:]...))
+			return
+		}
+		writeError(w, http.StatusNotFound, "Endpoint not found")
 	case "return-policies":
 		h.routeReturnPolicies(w, r, parts)
 	case "shipping-profiles":
 		h.routeShippingProfiles(w, r, parts)
 	case "payment-account":
 		if len(parts) >= 3 && parts[2] == "ledger-entries" {
-			h.GetLedgerEntries(w, r)
+			switch {
+			case len(parts) == 3:
+				h.GetLedgerEntries(w, r)
+			case len(parts) == 4 && parts[3] == "payments":
+				h.GetLedgerEntryPayments(w, r)
+			case len(parts) == 4:
+				h.GetLedgerEntry(w, r)
+			default:
+				writeError(w, http.StatusNotFound, "Endpoint not found")
+			}
 			return
 		}
 		writeError(w, http.StatusNotFound, "Endpoint not found")
@@ -257,7 +334,7 @@ func (h *Handler) routeShops(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		}
 	case "readiness-state-definitions":
-		h.GetReadinessStateDefinitions(w, r)
+		h.routeReadinessStateDefinitions(w, r, parts)
 	default:
 		writeError(w, http.StatusNotFound, "Endpoint not found")
 	}
@@ -304,6 +381,12 @@ func (h *Handler) routeShopListings(w http.ResponseWriter, r *http.Request, part
 		return
 	}
 
+	// /shops/{id}/listings/{listing_id}/transactions
+	if len(parts) == 4 && parts[3] == "transactions" {
+		h.GetListingTransactions(w, r)
+		return
+	}
+
 	// /shops/{id}/listings/{listing_id}/images
 	if len(parts) >= 4 && parts[3] == "images" {
 		if len(parts) == 4 {
@@ -352,7 +435,14 @@ func (h *Handler) routeShopListings(w http.ResponseWriter, r *http.Request, part
 
 	// /shops/{id}/listings/{listing_id}/inventory
 	if len(parts) == 4 && parts[3] == "inventory" {
-		h.GetListingInventory(w, r)
+		switch r.Method {
+		case http.MethodGet:
+			h.GetListingInventory(w, r)
+		case http.MethodPut:
+			h.UpdateListingInventory(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
 		return
 	}
 
@@ -400,6 +490,8 @@ func (h *Handler) routeShopListings(w http.ResponseWriter, r *http.Request, part
 		switch r.Method {
 		case http.MethodGet:
 			h.GetListingTranslation(w, r)
+		case http.MethodPost:
+			h.CreateListingTranslation(w, r)
 		case http.MethodPut:
 			h.UpdateListingTranslation(w, r)
 		default:
@@ -427,6 +519,19 @@ func (h *Handler) routeShopListings(w http.ResponseWriter, r *http.Request, part
 		return
 	}
 
+	// /shops/{id}/listings/{listing_id}/properties/{property_id}
+	if len(parts) == 5 && parts[3] == "properties" {
+		switch r.Method {
+		case http.MethodPut:
+			h.UpdateListingProperty(w, r)
+		case http.MethodDelete:
+			h.DeleteListingProperty(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
 	writeError(w, http.StatusNotFound, "Endpoint not found")
 }
 
@@ -449,6 +554,12 @@ func (h *Handler) routeShopReceipts(w http.ResponseWriter, r *http.Request, part
 		return
 	}
 
+	// /shops/{id}/receipts/{receipt_id}/listings
+	if len(parts) == 4 && parts[3] == "listings" {
+		h.GetReceiptListings(w, r)
+		return
+	}
+
 	// /shops/{id}/receipts/{receipt_id}/tracking
 	if len(parts) == 4 && parts[3] == "tracking" && r.Method == http.MethodPost {
 		h.CreateReceiptTracking(w, r)
@@ -496,7 +607,44 @@ func (h *Handler) routeShopSections(w http.ResponseWriter, r *http.Request, part
 		return
 	}
 	if len(parts) == 3 {
-		h.GetShopSection(w, r)
+		switch r.Method {
+		case http.MethodGet:
+			h.GetShopSection(w, r)
+		case http.MethodPut:
+			h.UpdateShopSection(w, r)
+		case http.MethodDelete:
+			h.DeleteShopSection(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, "Endpoint not found")
+}
+
+func (h *Handler) routeReadinessStateDefinitions(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetReadinessStateDefinitions(w, r)
+		case http.MethodPost:
+			h.CreateReadinessStateDefinition(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+	if len(parts) == 3 {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetReadinessStateDefinition(w, r)
+		case http.MethodPut:
+			h.UpdateReadinessStateDefinition(w, r)
+		case http.MethodDelete:
+			h.DeleteReadinessStateDefinition(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
 		return
 	}
 	writeError(w, http.StatusNotFound, "Endpoint not found")
@@ -514,8 +662,29 @@ func (h *Handler) routeReturnPolicies(w http.ResponseWriter, r *http.Request, pa
 		}
 		return
 	}
+	if len(parts) == 3 && parts[2] == "consolidate" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			return
+		}
+		h.ConsolidateReturnPolicies(w, r)
+		return
+	}
 	if len(parts) == 3 {
-		h.GetReturnPolicy(w, r)
+		switch r.Method {
+		case http.MethodGet:
+			h.GetReturnPolicy(w, r)
+		case http.MethodPut:
+			h.UpdateReturnPolicy(w, r)
+		case http.MethodDelete:
+			h.DeleteReturnPolicy(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+	if len(parts) == 4 && parts[3] == "listings" {
+		h.GetListingsByReturnPolicy(w, r)
 		return
 	}
 	writeError(w, http.StatusNotFound, "Endpoint not found")
@@ -537,6 +706,8 @@ func (h *Handler) routeShippingProfiles(w http.ResponseWriter, r *http.Request,
 		switch r.Method {
 		case http.MethodGet:
 			h.GetShippingProfile(w, r)
+		case http.MethodPut:
+			h.UpdateShippingProfile(w, r)
 		case http.MethodDelete:
 			h.DeleteShippingProfile(w, r)
 		default:
@@ -544,5 +715,136 @@ func (h *Handler) routeShippingProfiles(w http.ResponseWriter, r *http.Request,
 		}
 		return
 	}
+	// /shops/{id}/shipping-profiles/{pid}/destinations[/{did}]
+	if len(parts) >= 4 && parts[3] == "destinations" {
+		if len(parts) == 4 {
+			switch r.Method {
+			case http.MethodGet:
+				h.GetShippingProfileDestinations(w, r)
+			case http.MethodPost:
+				h.CreateShippingProfileDestination(w, r)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			}
+			return
+		}
+		if len(parts) == 5 {
+			switch r.Method {
+			case http.MethodPut:
+				h.UpdateShippingProfileDestination(w, r)
+			case http.MethodDelete:
+				h.DeleteShippingProfileDestination(w, r)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			}
+			return
+		}
+	}
+	// /shops/{id}/shipping-profiles/{pid}/upgrades[/{uid}]
+	if len(parts) >= 4 && parts[3] == "upgrades" {
+		if len(parts) == 4 {
+			switch r.Method {
+			case http.MethodGet:
+				h.GetShippingProfileUpgrades(w, r)
+			case http.MethodPost:
+				h.CreateShippingProfileUpgrade(w, r)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			}
+			return
+		}
+		if len(parts) == 5 {
+			switch r.Method {
+			case http.MethodPut:
+				h.UpdateShippingProfileUpgrade(w, r)
+			case http.MethodDelete:
+				h.DeleteShippingProfileUpgrade(w, r)
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			}
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Endpoint not found")
+}
+
+func (h *Handler) routeAdminClock(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.GetClock(w, r)
+	case http.MethodPost:
+		h.UpdateClock(w, r)
+	case http.MethodDelete:
+		h.ResetClock(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *Handler) routeAdminFaults(w http.ResponseWriter, r *http.Request) {
+	faultPath := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/faults"), "/")
+	if faultPath == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.ListFaults(w, r)
+		case http.MethodPost:
+			h.CreateFault(w, r)
+		case http.MethodDelete:
+			h.ClearFaults(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	// /admin/faults/{fault_id}
+	if !strings.Contains(faultPath, "/") {
+		if r.Method == http.MethodDelete {
+			h.DeleteFault(w, r)
+			return
+		}
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "Endpoint not found")
+}
+
+func (h *Handler) routeAdminSnapshots(w http.ResponseWriter, r *http.Request) {
+	snapPath := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/snapshots"), "/")
+	if snapPath == "" {
+		if r.Method == http.MethodGet {
+			h.ListSnapshots(w, r)
+			return
+		}
+		writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	parts := strings.Split(snapPath, "/")
+
+	// /admin/snapshots/{name}
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodPost:
+			h.CreateSnapshot(w, r, parts[0])
+		case http.MethodDelete:
+			h.DeleteSnapshot(w, r, parts[0])
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+		return
+	}
+
+	// /admin/snapshots/{name}/restore
+	if len(parts) == 2 && parts[1] == "restore" {
+		if r.Method == http.MethodPost {
+			h.RestoreSnapshot(w, r, parts[0])
+			return
+		}
+		writeError(w, http.StatusMethodNotAllowed, "POST only")
+		return
+	}
+
 	writeError(w, http.StatusNotFound, "Endpoint not found")
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"regexp"
 
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 )
@@ -31,14 +32,8 @@ func (h *Handler) GetShippingProfile(w http.ResponseWriter, r *http.Request) {
 	if !requireScope(w, r, "shops_r") {
 		return
 	}
-	profileID, ok := extractPathID(r.URL.Path, "shipping-profiles")
+	profile, ok := h.shopShippingProfile(w, r)
 	if !ok {
-		writeError(w, http.StatusBadRequest, "Invalid shipping_profile_id")
-		return
-	}
-	profile, found := h.Store.GetShippingProfile(profileID)
-	if !found {
-		writeError(w, http.StatusNotFound, "Shipping profile not found")
 		return
 	}
 	writeJSON(w, http.StatusOK, profile)
@@ -86,13 +81,467 @@ func (h *Handler) DeleteShippingProfile(w http.ResponseWriter, r *http.Request)
 	if !requireScope(w, r, "shops_w") {
 		return
 	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	if !h.Store.DeleteShippingProfile(profile.ShippingProfileID) {
+		writeError(w, http.StatusNotFound, "Shipping profile not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PUT /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}
+func (h *Handler) UpdateShippingProfile(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateShippingProfileRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Title != nil && *req.Title == "" {
+		writeError(w, http.StatusBadRequest, "title cannot be empty")
+		return
+	}
+	if req.OriginCountryISO != nil && !countryISOPattern.MatchString(*req.OriginCountryISO) {
+		writeError(w, http.StatusBadRequest, "origin_country_iso must be a two-letter ISO country code")
+		return
+	}
+	updated, _ := h.Store.UpdateShippingProfile(profile.ShippingProfileID, req)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// countryISOPattern matches an ISO 3166-1 alpha-2 country code.
+var countryISOPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+var destinationRegions = map[string]bool{"eu": true, "non_eu": true, "none": true}
+
+// shopShippingProfile resolves the shop and profile IDs in the path to a
+// live profile owned by that shop. On failure it writes the error response
+// and returns false.
+func (h *Handler) shopShippingProfile(w http.ResponseWriter, r *http.Request) (*models.ShopShippingProfile, bool) {
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return nil, false
+	}
 	profileID, ok := extractPathID(r.URL.Path, "shipping-profiles")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid shipping_profile_id")
-		return
+		return nil, false
 	}
-	if !h.Store.DeleteShippingProfile(profileID) {
+	shop, found := h.Store.GetShop(shopID)
+	if !found {
+		writeError(w, http.StatusNotFound, "Shop not found")
+		return nil, false
+	}
+	p, found := h.Store.GetShippingProfile(profileID)
+	if !found || p.IsDeleted || p.UserID != shop.UserID {
 		writeError(w, http.StatusNotFound, "Shipping profile not found")
+		return nil, false
+	}
+	return p, true
+}
+
+// shippingCurrency is the currency a profile's costs are quoted in: that of
+// the shop it belongs to.
+func (h *Handler) shippingCurrency(r *http.Request) string {
+	shopID, _ := extractPathID(r.URL.Path, "shops")
+	if shop, found := h.Store.GetShop(shopID); found && shop.CurrencyCode != "" {
+		return shop.CurrencyCode
+	}
+	return "USD"
+}
+
+// validateCarrier checks the carrier, mail class and delivery window shared
+// by destinations and upgrades. A carrier needs one of its own mail classes;
+// without one, the delivery window must be given explicitly.
+func validateCarrier(carrierID *int, mailClass *string, minDays, maxDays *int) string {
+	hasCarrier := carrierID != nil && *carrierID != 0
+	hasMailClass := mailClass != nil && *mailClass != ""
+	if hasCarrier {
+		var carrier *models.ShippingCarrier
+		for i := range shippingCarriers {
+			if shippingCarriers[i].ShippingCarrierID == *carrierID {
+				carrier = &shippingCarriers[i]
+				break
+			}
+		}
+		if carrier == nil {
+			return "Invalid shipping_carrier_id"
+		}
+		if !hasMailClass {
+			return "mail_class is required when shipping_carrier_id is set"
+		}
+		found := false
+		for _, c := range append(carrier.DomesticClasses, carrier.InternationalClasses...) {
+			if c.MailClassKey == *mailClass {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "mail_class " + *mailClass + " is not offered by " + carrier.Name
+		}
+	} else {
+		if hasMailClass {
+			return "mail_class requires a shipping_carrier_id"
+		}
+		if minDays == nil || maxDays == nil {
+			return "min_delivery_days and max_delivery_days are required when no shipping_carrier_id is set"
+		}
+	}
+	if minDays != nil && (*minDays < 1 || *minDays > 45) {
+		return "min_delivery_days must be between 1 and 45"
+	}
+	if maxDays != nil && (*maxDays < 1 || *maxDays > 45) {
+		return "max_delivery_days must be between 1 and 45"
+	}
+	if minDays != nil && maxDays != nil && *minDays > *maxDays {
+		return "min_delivery_days cannot exceed max_delivery_days"
+	}
+	return ""
+}
+
+// applyDestination merges req into d and validates the result.
+func applyDestination(d *models.ShopShippingProfileDestination, req models.ShippingProfileDestinationRequest, currency string) string {
+	if req.PrimaryCost != nil {
+		if *req.PrimaryCost < 0 {
+			return "primary_cost cannot be negative"
+		}
+		d.PrimaryCost = models.NewMoney(*req.PrimaryCost, currency)
+	}
+	if req.SecondaryCost != nil {
+		if *req.SecondaryCost < 0 {
+			return "secondary_cost cannot be negative"
+		}
+		d.SecondaryCost = models.NewMoney(*req.SecondaryCost, currency)
+	}
+	if req.DestinationCountryISO != nil {
+		d.DestinationCountryISO = *req.DestinationCountryISO
+	}
+	if req.DestinationRegion != nil {
+		d.DestinationRegion = *req.DestinationRegion
+	}
+	if req.ShippingCarrierID != nil {
+		d.ShippingCarrierID = req.ShippingCarrierID
+	}
+	if req.MailClass != nil {
+		d.MailClass = req.MailClass
+	}
+	if req.MinDeliveryDays != nil {
+		d.MinDeliveryDays = req.MinDeliveryDays
+	}
+	if req.MaxDeliveryDays != nil {
+		d.MaxDeliveryDays = req.MaxDeliveryDays
+	}
+
+	if d.DestinationRegion == "" {
+		d.DestinationRegion = "none"
+	}
+	if !destinationRegions[d.DestinationRegion] {
+		return "destination_region must be one of eu, non_eu, none"
+	}
+	if d.DestinationCountryISO != "" {
+		if d.DestinationRegion != "none" {
+			return "Only one of destination_country_iso or destination_region may be set"
+		}
+		if !countryISOPattern.MatchString(d.DestinationCountryISO) {
+			return "destination_country_iso must be a two-letter ISO country code"
+		}
+	}
+	return validateCarrier(d.ShippingCarrierID, d.MailClass, d.MinDeliveryDays, d.MaxDeliveryDays)
+}
+
+// duplicateDestination reports whether another destination of profile
+// already ships to the same country or region as d.
+func duplicateDestination(profile *models.ShopShippingProfile, d *models.ShopShippingProfileDestination) bool {
+	for _, other := range profile.ShippingProfileDestinations {
+		if other.ShippingProfileDestinationID != d.ShippingProfileDestinationID &&
+			other.DestinationCountryISO == d.DestinationCountryISO &&
+			other.DestinationRegion == d.DestinationRegion {
+			return true
+		}
+	}
+	return false
+}
+
+// GET /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/destinations
+func (h *Handler) GetShippingProfileDestinations(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_r") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	limit := queryInt(r, "limit", 25)
+	offset := queryInt(r, "offset", 0)
+	destinations, total := h.Store.GetShippingDestinations(profile.ShippingProfileID, limit, offset)
+	if destinations == nil {
+		destinations = []models.ShopShippingProfileDestination{}
+	}
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   total,
+		Results: destinations,
+	})
+}
+
+// POST /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/destinations
+func (h *Handler) CreateShippingProfileDestination(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	var req models.ShippingProfileDestinationRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.PrimaryCost == nil || req.SecondaryCost == nil {
+		writeError(w, http.StatusBadRequest, "primary_cost and secondary_cost are required")
+		return
+	}
+	var d models.ShopShippingProfileDestination
+	if msg := applyDestination(&d, req, h.shippingCurrency(r)); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if duplicateDestination(profile, &d) {
+		writeError(w, http.StatusBadRequest, "A destination for this country or region already exists on the shipping profile")
+		return
+	}
+	created, _ := h.Store.AddShippingDestination(profile.ShippingProfileID, d)
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// PUT /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/destinations/{shipping_profile_destination_id}
+func (h *Handler) UpdateShippingProfileDestination(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	destID, ok := extractPathID(r.URL.Path, "destinations")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shipping_profile_destination_id")
+		return
+	}
+	var d *models.ShopShippingProfileDestination
+	for _, existing := range profile.ShippingProfileDestinations {
+		if existing.ShippingProfileDestinationID == destID {
+			d = &existing
+			break
+		}
+	}
+	if d == nil {
+		writeError(w, http.StatusNotFound, "Shipping profile destination not found")
+		return
+	}
+	var req models.ShippingProfileDestinationRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	// Choosing one kind of destination clears the other.
+	if req.DestinationCountryISO != nil && *req.DestinationCountryISO != "" && req.DestinationRegion == nil {
+		d.DestinationRegion = "none"
+	}
+	if req.DestinationRegion != nil && *req.DestinationRegion != "none" && req.DestinationCountryISO == nil {
+		d.DestinationCountryISO = ""
+	}
+	if msg := applyDestination(d, req, h.shippingCurrency(r)); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if duplicateDestination(profile, d) {
+		writeError(w, http.StatusBadRequest, "A destination for this country or region already exists on the shipping profile")
+		return
+	}
+	if !h.Store.UpdateShippingDestination(profile.ShippingProfileID, *d) {
+		writeError(w, http.StatusNotFound, "Shipping profile destination not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// DELETE /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/destinations/{shipping_profile_destination_id}
+func (h *Handler) DeleteShippingProfileDestination(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	destID, ok := extractPathID(r.URL.Path, "destinations")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shipping_profile_destination_id")
+		return
+	}
+	if !h.Store.DeleteShippingDestination(profile.ShippingProfileID, destID) {
+		writeError(w, http.StatusNotFound, "Shipping profile destination not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyUpgrade merges req into u and validates the result.
+func applyUpgrade(u *models.ShopShippingProfileUpgrade, req models.ShippingProfileUpgradeRequest, currency string) string {
+	if req.Type != nil {
+		if *req.Type != 0 && *req.Type != 1 {
+			return "type must be 0 (domestic) or 1 (international)"
+		}
+		u.Type = *req.Type
+	}
+	if req.UpgradeName != nil {
+		if *req.UpgradeName == "" {
+			return "upgrade_name cannot be empty"
+		}
+		u.UpgradeName = *req.UpgradeName
+	}
+	if req.Price != nil {
+		if *req.Price < 0 {
+			return "price cannot be negative"
+		}
+		u.Price = models.NewMoney(*req.Price, currency)
+	}
+	if req.SecondaryPrice != nil {
+		if *req.SecondaryPrice < 0 {
+			return "secondary_price cannot be negative"
+		}
+		u.SecondaryPrice = models.NewMoney(*req.SecondaryPrice, currency)
+	}
+	if req.ShippingCarrierID != nil {
+		u.ShippingCarrierID = req.ShippingCarrierID
+	}
+	if req.MailClass != nil {
+		u.MailClass = req.MailClass
+	}
+	if req.MinDeliveryDays != nil {
+		u.MinDeliveryDays = req.MinDeliveryDays
+	}
+	if req.MaxDeliveryDays != nil {
+		u.MaxDeliveryDays = req.MaxDeliveryDays
+	}
+	return validateCarrier(u.ShippingCarrierID, u.MailClass, u.MinDeliveryDays, u.MaxDeliveryDays)
+}
+
+// GET /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/upgrades
+func (h *Handler) GetShippingProfileUpgrades(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_r") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	upgrades := h.Store.GetShippingUpgrades(profile.ShippingProfileID)
+	if upgrades == nil {
+		upgrades = []models.ShopShippingProfileUpgrade{}
+	}
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   len(upgrades),
+		Results: upgrades,
+	})
+}
+
+// POST /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/upgrades
+func (h *Handler) CreateShippingProfileUpgrade(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	var req models.ShippingProfileUpgradeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Type == nil || req.UpgradeName == nil || req.Price == nil || req.SecondaryPrice == nil {
+		writeError(w, http.StatusBadRequest, "type, upgrade_name, price and secondary_price are required")
+		return
+	}
+	var u models.ShopShippingProfileUpgrade
+	if msg := applyUpgrade(&u, req, h.shippingCurrency(r)); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	created, _ := h.Store.AddShippingUpgrade(profile.ShippingProfileID, u)
+	writeJSON(w, http.StatusOK, created)
+}
+
+// PUT /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/upgrades/{upgrade_id}
+func (h *Handler) UpdateShippingProfileUpgrade(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	upgradeID, ok := extractPathID(r.URL.Path, "upgrades")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid upgrade_id")
+		return
+	}
+	var u *models.ShopShippingProfileUpgrade
+	for _, existing := range profile.ShippingProfileUpgrades {
+		if existing.UpgradeID == upgradeID {
+			u = &existing
+			break
+		}
+	}
+	if u == nil {
+		writeError(w, http.StatusNotFound, "Shipping profile upgrade not found")
+		return
+	}
+	var req models.ShippingProfileUpgradeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := applyUpgrade(u, req, h.shippingCurrency(r)); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if !h.Store.UpdateShippingUpgrade(profile.ShippingProfileID, *u) {
+		writeError(w, http.StatusNotFound, "Shipping profile upgrade not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// DELETE /v3/application/shops/{shop_id}/shipping-profiles/{shipping_profile_id}/upgrades/{upgrade_id}
+func (h *Handler) DeleteShippingProfileUpgrade(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	profile, ok := h.shopShippingProfile(w, r)
+	if !ok {
+		return
+	}
+	upgradeID, ok := extractPathID(r.URL.Path, "upgrades")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid upgrade_id")
+		return
+	}
+	if !h.Store.DeleteShippingUpgrade(profile.ShippingProfileID, upgradeID) {
+		writeError(w, http.StatusNotFound, "Shipping profile upgrade not found")
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 )
 
 // GET /v3/application/shops/{shop_id}
@@ -112,8 +115,63 @@ func (h *Handler) CreateShopSection(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, sec)
 }
 
-// GET /v3/application/shops/{shop_id}/return-policies
+// PUT /v3/application/shops/{shop_id}/sections/{shop_section_id}
+func (h *Handler) UpdateShopSection(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	sectionID, ok := extractPathID(r.URL.Path, "sections")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid section_id")
+		return
+	}
+	var body struct {
+		Title string `json:"title"`
+	}
+	if err := decodeJSON(r, &body); err != nil || body.Title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	sec, found := h.Store.UpdateShopSection(shopID, sectionID, body.Title)
+	if !found {
+		writeError(w, http.StatusNotFound, "Section not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, sec)
+}
+
+// DELETE /v3/application/shops/{shop_id}/sections/{shop_section_id}
+func (h *Handler) DeleteShopSection(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	sectionID, ok := extractPathID(r.URL.Path, "sections")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid section_id")
+		return
+	}
+	if !h.Store.DeleteShopSection(shopID, sectionID) {
+		writeError(w, http.StatusNotFound, "Section not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /v3/application/shops/{shop_id}/policies/return
 func (h *Handler) GetReturnPolicies(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_r") {
+		return
+	}
 	shopID, ok := extractPathID(r.URL.Path, "shops")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid shop_id")
@@ -126,7 +184,59 @@ func (h *Handler) GetReturnPolicies(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// POST /v3/application/shops/{shop_id}/return-policies
+// returnDeadlines are the return windows, in days, Etsy accepts.
+var returnDeadlines = map[int]bool{7: true, 14: true, 21: true, 30: true, 45: true, 60: true, 90: true}
+
+type returnPolicyBody struct {
+	AcceptsReturns   bool `json:"accepts_returns"`
+	AcceptsExchanges bool `json:"accepts_exchanges"`
+	ReturnDeadline   *int `json:"return_deadline"`
+}
+
+func (b *returnPolicyBody) validate() string {
+	if b.ReturnDeadline == nil {
+		return ""
+	}
+	if !b.AcceptsReturns && !b.AcceptsExchanges {
+		return "return_deadline must be null when the policy accepts neither returns nor exchanges"
+	}
+	if !returnDeadlines[*b.ReturnDeadline] {
+		return "return_deadline must be one of 7, 14, 21, 30, 45, 60, 90"
+	}
+	return ""
+}
+
+// returnPolicyID reads the policy ID from either the spec's
+// /policies/return/{id} path or the legacy /return-policies/{id} one.
+func returnPolicyID(path string) (int64, bool) {
+	if id, ok := extractPathID(path, "return-policies"); ok {
+		return id, true
+	}
+	return extractPathID(path, "return")
+}
+
+// shopReturnPolicy resolves the shop and policy IDs in the path to a policy
+// of that shop. On failure it writes the error response and returns false.
+func (h *Handler) shopReturnPolicy(w http.ResponseWriter, r *http.Request) (*models.ShopReturnPolicy, bool) {
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return nil, false
+	}
+	policyID, ok := returnPolicyID(r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid return_policy_id")
+		return nil, false
+	}
+	p, found := h.Store.GetReturnPolicy(policyID)
+	if !found || p.ShopID != shopID {
+		writeError(w, http.StatusNotFound, "Return policy not found")
+		return nil, false
+	}
+	return p, true
+}
+
+// POST /v3/application/shops/{shop_id}/policies/return
 func (h *Handler) CreateReturnPolicy(w http.ResponseWriter, r *http.Request) {
 	if !requireScope(w, r, "shops_w") {
 		return
@@ -136,30 +246,137 @@ func (h *Handler) CreateReturnPolicy(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Invalid shop_id")
 		return
 	}
-	var body struct {
-		AcceptsReturns   bool `json:"accepts_returns"`
-		AcceptsExchanges bool `json:"accepts_exchanges"`
-		ReturnDeadline   *int `json:"return_deadline"`
-	}
+	var body returnPolicyBody
 	if err := decodeJSON(r, &body); err != nil {
 		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
+	if msg := body.validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if dup, found := h.Store.FindIdenticalReturnPolicy(shopID, 0, body.AcceptsReturns, body.AcceptsExchanges, body.ReturnDeadline); found {
+		writeDuplicateReturnPolicy(w, http.StatusBadRequest, dup.ReturnPolicyID)
+		return
+	}
 	p := h.Store.CreateReturnPolicy(shopID, body.AcceptsReturns, body.AcceptsExchanges, body.ReturnDeadline)
 	writeJSON(w, http.StatusCreated, p)
 }
 
-// GET /v3/application/shops/{shop_id}/return-policies/{return_policy_id}
+// writeDuplicateReturnPolicy answers a create or update that would leave two
+// identical return policies in a shop. The spec documents 409 for updates
+// only, so creates get a 400.
+func writeDuplicateReturnPolicy(w http.ResponseWriter, status int, existingID int64) {
+	writeError(w, status, fmt.Sprintf("An identical return policy already exists: %d. Consolidate the policies instead", existingID))
+}
+
+// GET /v3/application/shops/{shop_id}/policies/return/{return_policy_id}
 func (h *Handler) GetReturnPolicy(w http.ResponseWriter, r *http.Request) {
-	policyID, ok := extractPathID(r.URL.Path, "return-policies")
+	if !requireScope(w, r, "shops_r") {
+		return
+	}
+	p, ok := h.shopReturnPolicy(w, r)
 	if !ok {
-		writeError(w, http.StatusBadRequest, "Invalid return_policy_id")
 		return
 	}
-	p, found := h.Store.GetReturnPolicy(policyID)
+	writeJSON(w, http.StatusOK, p)
+}
+
+// PUT /v3/application/shops/{shop_id}/policies/return/{return_policy_id}
+func (h *Handler) UpdateReturnPolicy(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	p, ok := h.shopReturnPolicy(w, r)
+	if !ok {
+		return
+	}
+	var body returnPolicyBody
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if msg := body.validate(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if dup, found := h.Store.FindIdenticalReturnPolicy(p.ShopID, p.ReturnPolicyID, body.AcceptsReturns, body.AcceptsExchanges, body.ReturnDeadline); found {
+		writeDuplicateReturnPolicy(w, http.StatusConflict, dup.ReturnPolicyID)
+		return
+	}
+	updated, _ := h.Store.UpdateReturnPolicy(p.ReturnPolicyID, body.AcceptsReturns, body.AcceptsExchanges, body.ReturnDeadline)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DELETE /v3/application/shops/{shop_id}/policies/return/{return_policy_id}
+func (h *Handler) DeleteReturnPolicy(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	p, ok := h.shopReturnPolicy(w, r)
+	if !ok {
+		return
+	}
+	if n := len(h.Store.GetListingsByReturnPolicy(p.ReturnPolicyID)); n > 0 {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("Return policy %d is used by %d listings and cannot be deleted", p.ReturnPolicyID, n))
+		return
+	}
+	h.Store.DeleteReturnPolicy(p.ReturnPolicyID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /v3/application/shops/{shop_id}/policies/return/{return_policy_id}/listings
+func (h *Handler) GetListingsByReturnPolicy(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "listings_r") {
+		return
+	}
+	p, ok := h.shopReturnPolicy(w, r)
+	if !ok {
+		return
+	}
+	listings := h.Store.GetListingsByReturnPolicy(p.ReturnPolicyID)
+	writeJSON(w, http.StatusOK, models.PaginatedResponse{
+		Count:   len(listings),
+		Results: listings,
+	})
+}
+
+// POST /v3/application/shops/{shop_id}/policies/return/consolidate
+func (h *Handler) ConsolidateReturnPolicies(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "shops_w") {
+		return
+	}
+	shopID, ok := extractPathID(r.URL.Path, "shops")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid shop_id")
+		return
+	}
+	var body struct {
+		SourceReturnPolicyID      int64 `json:"source_return_policy_id"`
+		DestinationReturnPolicyID int64 `json:"destination_return_policy_id"`
+	}
+	if err := decodeJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if body.SourceReturnPolicyID < 1 || body.DestinationReturnPolicyID < 1 {
+		writeError(w, http.StatusBadRequest, "source_return_policy_id and destination_return_policy_id are required")
+		return
+	}
+	if body.SourceReturnPolicyID == body.DestinationReturnPolicyID {
+		writeError(w, http.StatusBadRequest, "source_return_policy_id and destination_return_policy_id must differ")
+		return
+	}
+	for _, id := range []int64{body.SourceReturnPolicyID, body.DestinationReturnPolicyID} {
+		if p, found := h.Store.GetReturnPolicy(id); !found || p.ShopID != shopID {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("Return policy %d not found", id))
+			return
+		}
+	}
+	dest, found := h.Store.ConsolidateReturnPolicies(body.SourceReturnPolicyID, body.DestinationReturnPolicyID)
 	if !found {
 		writeError(w, http.StatusNotFound, "Return policy not found")
 		return
 	}
-	writeJSON(w, http.StatusOK, p)
+	writeJSON(w, http.StatusOK, dest)
 }
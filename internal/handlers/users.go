@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/vlah-software-house/etsy-mock-api/internal/middleware"
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 )
 
@@ -24,45 +26,117 @@ func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, user)
 }
 
+// addressOwner returns the user whose addresses the request may touch: the
+// token's user. On /users/{user_id}/... paths the user_id must match it. On
+// failure it writes the error response and returns false.
+func addressOwner(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	tokenUserID, ok := middleware.GetUserID(r)
+	if !ok {
+		writeError(w, http.StatusForbidden, "This endpoint requires OAuth2. Provide a Bearer token.")
+		return 0, false
+	}
+	if strings.HasPrefix(r.URL.Path, "/v3/application/users/") {
+		userID, ok := extractPathID(r.URL.Path, "users")
+		if !ok {
+			writeError(w, http.StatusBadRequest, "Invalid user_id")
+			return 0, false
+		}
+		if userID != tokenUserID {
+			writeError(w, http.StatusForbidden, "You do not have permission to access this user's addresses")
+			return 0, false
+		}
+	}
+	return tokenUserID, true
+}
+
+// userAddress resolves the address ID in the path to an address of the
+// token's user. On failure it writes the error response and returns false.
+func (h *Handler) userAddress(w http.ResponseWriter, r *http.Request) (*models.UserAddress, bool) {
+	userID, ok := addressOwner(w, r)
+	if !ok {
+		return nil, false
+	}
+	addressID, ok := extractPathID(r.URL.Path, "addresses")
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Invalid user_address_id")
+		return nil, false
+	}
+	addr, found := h.Store.GetUserAddress(addressID)
+	if !found {
+		writeError(w, http.StatusNotFound, "User address not found")
+		return nil, false
+	}
+	if addr.UserID != userID {
+		writeError(w, http.StatusForbidden, "You do not have permission to access this user's addresses")
+		return nil, false
+	}
+	return addr, true
+}
+
+// GET /v3/application/user/addresses
 // GET /v3/application/users/{user_id}/addresses
 func (h *Handler) GetUserAddresses(w http.ResponseWriter, r *http.Request) {
 	if !requireScope(w, r, "address_r") {
 		return
 	}
-	userID, ok := extractPathID(r.URL.Path, "users")
+	userID, ok := addressOwner(w, r)
 	if !ok {
-		writeError(w, http.StatusBadRequest, "Invalid user_id")
 		return
 	}
-	addrs := h.Store.GetUserAddresses(userID)
+	limit := queryInt(r, "limit", 25)
+	offset := queryInt(r, "offset", 0)
+
+	addrs, total := h.Store.GetUserAddresses(userID, limit, offset)
 	if addrs == nil {
 		addrs = []models.UserAddress{}
 	}
 	writeJSON(w, http.StatusOK, models.PaginatedResponse{
-		Count:   len(addrs),
+		Count:   total,
 		Results: addrs,
 	})
 }
 
+// GET /v3/application/user/addresses/{user_address_id}
+// GET /v3/application/users/{user_id}/addresses/{user_address_id}
+func (h *Handler) GetUserAddress(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "address_r") {
+		return
+	}
+	addr, ok := h.userAddress(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, addr)
+}
+
+// DELETE /v3/application/user/addresses/{user_address_id}
+// DELETE /v3/application/users/{user_id}/addresses/{user_address_id}
+func (h *Handler) DeleteUserAddress(w http.ResponseWriter, r *http.Request) {
+	if !requireScope(w, r, "address_w") {
+		return
+	}
+	addr, ok := h.userAddress(w, r)
+	if !ok {
+		return
+	}
+	if !h.Store.DeleteUserAddress(addr.UserAddressID) {
+		writeError(w, http.StatusNotFound, "User address not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // GET /v3/application/users/{user_id}/shops
-func (h *Handler) GetUserShops(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetShopByOwnerUserID(w http.ResponseWriter, r *http.Request) {
 	userID, ok := extractPathID(r.URL.Path, "users")
 	if !ok {
 		writeError(w, http.StatusBadRequest, "Invalid user_id")
 		return
 	}
-
-	var shops []models.Shop
-	for _, shop := range h.Store.Shops {
-		if shop.UserID == userID {
-			shops = append(shops, *shop)
-		}
-	}
-	if shops == nil {
-		shops = []models.Shop{}
+	shop, found := h.Store.GetShopByOwner(userID)
+	if !found {
+		writeError(w, http.StatusNotFound, "User does not own a shop")
+		return
 	}
-	writeJSON(w, http.StatusOK, models.PaginatedResponse{
-		Count:   len(shops),
-		Results: shops,
-	})
+	writeJSON(w, http.StatusOK, shop)
 }
@@ -2,12 +2,14 @@ package middleware
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"log"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
 )
 
 type contextKey string
@@ -33,44 +35,69 @@ type APIKeyEntry struct {
 	SharedSecret string
 	Status       APIKeyStatus
 	Label        string // Human-readable label for logging
+	// Requests allowed per second and per day; 0 means DefaultPerSecond
+	// and DefaultPerDay.
+	PerSecond int
+	PerDay    int
 }
 
 // APIKeyStore holds registered API keys. Thread-safe.
 type APIKeyStore struct {
-	mu   sync.RWMutex
-	keys map[string]*APIKeyEntry // keyed by keystring
+	mu      sync.RWMutex
+	keys    map[string]*APIKeyEntry // keyed by keystring
+	buckets map[string]*rateBucket  // keyed by keystring
 }
 
 func NewAPIKeyStore() *APIKeyStore {
-	ks := &APIKeyStore{keys: make(map[string]*APIKeyEntry)}
+	return &APIKeyStore{keys: seedAPIKeys(), buckets: make(map[string]*rateBucket)}
+}
+
+// Reset restores the store to its pre-seeded set of API keys and refills
+// every rate limit bucket.
+func (ks *APIKeyStore) Reset() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = seedAPIKeys()
+	ks.buckets = make(map[string]*rateBucket)
+}
+
+func seedAPIKeys() map[string]*APIKeyEntry {
+	keys := make(map[string]*APIKeyEntry)
 
 	// Valid test keys
-	ks.keys["test-key"] = &APIKeyEntry{
+	keys["test-key"] = &APIKeyEntry{
 		Keystring: "test-key", SharedSecret: "test-secret",
 		Status: APIKeyValid, Label: "Test App (valid)",
 	}
-	ks.keys["alice-app"] = &APIKeyEntry{
+	keys["alice-app"] = &APIKeyEntry{
 		Keystring: "alice-app", SharedSecret: "alice-secret",
 		Status: APIKeyValid, Label: "Alice's App (valid)",
 	}
-	ks.keys["bob-app"] = &APIKeyEntry{
+	keys["bob-app"] = &APIKeyEntry{
 		Keystring: "bob-app", SharedSecret: "bob-secret",
 		Status: APIKeyValid, Label: "Bob's App (valid)",
 	}
 
 	// Banned/revoked app key
-	ks.keys["banned-app"] = &APIKeyEntry{
+	keys["banned-app"] = &APIKeyEntry{
 		Keystring: "banned-app", SharedSecret: "banned-secret",
 		Status: APIKeyBanned, Label: "Banned App (revoked)",
 	}
 
 	// Expired key
-	ks.keys["expired-app"] = &APIKeyEntry{
+	keys["expired-app"] = &APIKeyEntry{
 		Keystring: "expired-app", SharedSecret: "expired-secret",
 		Status: APIKeyExpired, Label: "Expired App",
 	}
 
-	return ks
+	// Key with low limits for testing throttling
+	keys["throttled-app"] = &APIKeyEntry{
+		Keystring: "throttled-app", SharedSecret: "throttled-secret",
+		Status: APIKeyValid, Label: "Throttled App (2/s, 20/day)",
+		PerSecond: 2, PerDay: 20,
+	}
+
+	return keys
 }
 
 // Validate checks an API key and returns the entry and an error message if invalid.
@@ -97,6 +124,14 @@ func (ks *APIKeyStore) Validate(keystring, sharedSecret string) (*APIKeyEntry, s
 	return entry, "", 0
 }
 
+// Get returns the entry registered for keystring.
+func (ks *APIKeyStore) Get(keystring string) (*APIKeyEntry, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	entry, ok := ks.keys[keystring]
+	return entry, ok
+}
+
 // Register adds or updates an API key in the store.
 func (ks *APIKeyStore) Register(entry *APIKeyEntry) {
 	ks.mu.Lock()
@@ -108,36 +143,62 @@ func (ks *APIKeyStore) Register(entry *APIKeyEntry) {
 type TokenEntry struct {
 	AccessToken  string
 	RefreshToken string
+	ClientID     string // keystring the token was issued to; empty for seeded tokens
 	UserID       int64
 	Scopes       []string
 	ExpiresAt    time.Time
 }
 
-// TokenStore holds mock OAuth tokens. Thread-safe.
+// AuthCode is an authorization code issued by /oauth/connect, waiting to
+// be exchanged for a token.
+type AuthCode struct {
+	Code          string
+	ClientID      string
+	RedirectURI   string
+	UserID        int64
+	Scope         string // space-separated, as requested
+	CodeChallenge string // S256
+	ExpiresAt     time.Time
+}
+
+// TokenStore holds mock OAuth tokens and authorization codes. Thread-safe.
 type TokenStore struct {
 	mu     sync.RWMutex
 	tokens map[string]*TokenEntry // keyed by access_token
+	codes  map[string]*AuthCode   // keyed by code
 }
 
 func NewTokenStore() *TokenStore {
-	ts := &TokenStore{tokens: make(map[string]*TokenEntry)}
+	return &TokenStore{tokens: seedTokens(), codes: make(map[string]*AuthCode)}
+}
+
+// Reset drops every issued token and code and restores the pre-seeded tokens.
+func (ts *TokenStore) Reset() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.tokens = seedTokens()
+	ts.codes = make(map[string]*AuthCode)
+}
+
+func seedTokens() map[string]*TokenEntry {
+	tokens := make(map[string]*TokenEntry)
 	// Pre-seed tokens for testing convenience
-	ts.tokens["test-token-alice"] = &TokenEntry{
+	tokens["test-token-alice"] = &TokenEntry{
 		AccessToken: "test-token-alice", RefreshToken: "refresh-alice",
-		UserID: 1001, Scopes: AllScopes(), ExpiresAt: time.Now().Add(24 * time.Hour),
+		UserID: 1001, Scopes: AllScopes(), ExpiresAt: clock.Now().Add(24 * time.Hour),
 	}
-	ts.tokens["test-token-bob"] = &TokenEntry{
+	tokens["test-token-bob"] = &TokenEntry{
 		AccessToken: "test-token-bob", RefreshToken: "refresh-bob",
-		UserID: 1002, Scopes: AllScopes(), ExpiresAt: time.Now().Add(24 * time.Hour),
+		UserID: 1002, Scopes: AllScopes(), ExpiresAt: clock.Now().Add(24 * time.Hour),
 	}
-	return ts
+	return tokens
 }
 
 func (ts *TokenStore) Get(accessToken string) (*TokenEntry, bool) {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 	t, ok := ts.tokens[accessToken]
-	if ok && time.Now().After(t.ExpiresAt) {
+	if ok && clock.Now().After(t.ExpiresAt) {
 		return nil, false
 	}
 	return t, ok
@@ -160,6 +221,28 @@ func (ts *TokenStore) Store(entry *TokenEntry) {
 	ts.tokens[entry.AccessToken] = entry
 }
 
+func (ts *TokenStore) StoreCode(code *AuthCode) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.codes[code.Code] = code
+}
+
+// TakeCode returns an unexpired authorization code and removes it, so each
+// code can be exchanged only once.
+func (ts *TokenStore) TakeCode(code string) (*AuthCode, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	c, ok := ts.codes[code]
+	if !ok {
+		return nil, false
+	}
+	delete(ts.codes, code)
+	if clock.Now().After(c.ExpiresAt) {
+		return nil, false
+	}
+	return c, true
+}
+
 func AllScopes() []string {
 	return []string{
 		"address_r", "address_w", "billing_r", "cart_r", "cart_w",
@@ -190,20 +273,20 @@ func MockAuth(tokenStore *TokenStore, keyStore *APIKeyStore) func(http.Handler)
 
 			// Validate x-api-key format: keystring:shared_secret
 			if apiKey == "" {
-				writeAuthError(w, http.StatusUnauthorized, "Missing x-api-key header. Format: keystring:shared_secret")
+				writeError(w, http.StatusUnauthorized, "Missing x-api-key header. Format: keystring:shared_secret")
 				return
 			}
 
 			parts := strings.SplitN(apiKey, ":", 2)
 			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-				writeAuthError(w, http.StatusUnauthorized, "Invalid x-api-key format. Expected keystring:shared_secret")
+				writeError(w, http.StatusUnauthorized, "Invalid x-api-key format. Expected keystring:shared_secret")
 				return
 			}
 
 			// Validate against registered API keys
 			_, errMsg, errStatus := keyStore.Validate(parts[0], parts[1])
 			if errMsg != "" {
-				writeAuthError(w, errStatus, errMsg)
+				writeError(w, errStatus, errMsg)
 				return
 			}
 
@@ -214,7 +297,7 @@ func MockAuth(tokenStore *TokenStore, keyStore *APIKeyStore) func(http.Handler)
 				token := authHeader[7:]
 				entry, ok := tokenStore.Get(token)
 				if !ok {
-					writeAuthError(w, http.StatusUnauthorized, "Invalid or expired OAuth access token")
+					writeError(w, http.StatusUnauthorized, "Invalid or expired OAuth access token")
 					return
 				}
 				ctx = context.WithValue(ctx, ContextScopes, entry.Scopes)
@@ -246,21 +329,12 @@ func GetUserID(r *http.Request) (int64, bool) {
 	return uid, ok
 }
 
-func writeAuthError(w http.ResponseWriter, status int, msg string) {
+// writeError answers with status and Etsy's {"error": msg} body. Every
+// middleware that refuses a request uses it.
+func writeError(w http.ResponseWriter, status int, msg string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	fmt.Fprintf(w, `{"error":"%s"}`, msg)
-}
-
-// RateLimitHeaders adds Etsy-style rate limit headers to every response.
-func RateLimitHeaders(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("x-limit-per-second", "10")
-		w.Header().Set("x-remaining-this-second", "9")
-		w.Header().Set("x-limit-per-day", "10000")
-		w.Header().Set("x-remaining-today", "9999")
-		next.ServeHTTP(w, r)
-	})
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
 }
 
 // RequestLogger logs each request with method, path, and duration.
@@ -277,7 +351,7 @@ func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-api-key")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, x-api-key, X-Mock-Status, X-Mock-Delay-Ms, X-Mock-Error")
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
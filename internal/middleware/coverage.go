@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/openapi"
+)
+
+// CountOperations records a hit for the spec operation each request matches,
+// so GET /admin/coverage can show which operations a test run exercised.
+// It goes outside every middleware that can reject a request, so rejected
+// calls are counted too.
+func CountOperations(spec *openapi.Spec, hits *openapi.Hits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if op, _ := spec.Match(r.Method, r.URL.Path); op != nil {
+				hits.Record(op.OperationID)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
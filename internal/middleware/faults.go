@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultRule makes requests matching Method and Path slow down or fail, so
+// clients' retry and backoff handling can be exercised against the mock.
+type FaultRule struct {
+	ID     int64  `json:"fault_id"`
+	Method string `json:"method,omitempty"` // empty matches every method
+	// Path is matched segment by segment: * matches any one segment and a
+	// trailing ** matches whatever follows. Paths not starting with /v3/ are
+	// taken as relative to /v3/application.
+	Path       string  `json:"path"`
+	Status     int     `json:"status,omitempty"`
+	Message    string  `json:"message,omitempty"`
+	RetryAfter int     `json:"retry_after,omitempty"` // seconds, sent as retry-after
+	DelayMs    int     `json:"delay_ms,omitempty"`
+	Percent    float64 `json:"percent,omitempty"`         // chance of applying; 0 means every call
+	NthCall    int     `json:"nth_call,omitempty"`        // apply on this matching call only; 0 means every call
+	Drop       bool    `json:"drop_connection,omitempty"` // close the connection halfway through the body
+	Calls      int     `json:"calls"`                     // matching requests seen so far
+}
+
+// FaultStore holds the registered fault rules. Thread-safe.
+type FaultStore struct {
+	mu     sync.Mutex
+	rules  []*FaultRule
+	nextID int64
+}
+
+func NewFaultStore() *FaultStore {
+	return &FaultStore{nextID: 1}
+}
+
+// Add registers rule and returns it with its ID assigned.
+func (fs *FaultStore) Add(rule FaultRule) FaultRule {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	rule.ID = fs.nextID
+	fs.nextID++
+	rule.Method = strings.ToUpper(rule.Method)
+	if !strings.HasPrefix(rule.Path, "/v3/") {
+		rule.Path = "/v3/application/" + strings.TrimPrefix(rule.Path, "/")
+	}
+	rule.Calls = 0
+	fs.rules = append(fs.rules, &rule)
+	return rule
+}
+
+func (fs *FaultStore) List() []FaultRule {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	out := make([]FaultRule, len(fs.rules))
+	for i, rule := range fs.rules {
+		out[i] = *rule
+	}
+	return out
+}
+
+func (fs *FaultStore) Delete(id int64) bool {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	for i, rule := range fs.rules {
+		if rule.ID == id {
+			fs.rules = append(fs.rules[:i], fs.rules[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (fs *FaultStore) Clear() {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.rules = nil
+}
+
+// hit counts a call against every rule matching the request. It returns the
+// total delay those rules add and the first one that fails the request, if
+// any.
+func (fs *FaultStore) hit(method, path string) (time.Duration, *FaultRule) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var delay time.Duration
+	var failure *FaultRule
+	for _, rule := range fs.rules {
+		if (rule.Method != "" && rule.Method != method) || !matchFaultPath(rule.Path, path) {
+			continue
+		}
+		rule.Calls++
+		if rule.NthCall > 0 && rule.Calls != rule.NthCall {
+			continue
+		}
+		if rule.Percent > 0 && rand.Float64()*100 >= rule.Percent {
+			continue
+		}
+		delay += time.Duration(rule.DelayMs) * time.Millisecond
+		if failure == nil && (rule.Status != 0 || rule.Drop) {
+			copied := *rule
+			failure = &copied
+		}
+	}
+	return delay, failure
+}
+
+func matchFaultPath(pattern, path string) bool {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range want {
+		if seg == "**" && i == len(want)-1 {
+			return true
+		}
+		if i >= len(got) || (seg != "*" && seg != got[i]) {
+			return false
+		}
+	}
+	return len(want) == len(got)
+}
+
+// Faults applies the rules in faults to every request outside /admin/.
+// Delays are served first; then a failing rule either answers with its
+// status or, for drop_connection, lets the handler run and cuts its
+// response off halfway.
+func Faults(faults *FaultStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/admin/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			delay, rule := faults.hit(r.Method, r.URL.Path)
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			switch {
+			case rule == nil:
+				next.ServeHTTP(w, r)
+			case rule.Drop:
+				dropMidBody(w, r, next)
+			default:
+				msg := rule.Message
+				if msg == "" {
+					msg = http.StatusText(rule.Status)
+				}
+				if rule.RetryAfter > 0 {
+					w.Header().Set("retry-after", strconv.Itoa(rule.RetryAfter))
+				}
+				writeError(w, rule.Status, msg)
+			}
+		})
+	}
+}
+
+// dropMidBody sends the status, headers and first half of next's response
+// under the full Content-Length, then closes the connection.
+func dropMidBody(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(rec, r)
+
+	body := rec.body.Bytes()
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(rec.status)
+	w.Write(body[:len(body)/2])
+
+	rc := http.NewResponseController(w)
+	rc.Flush()
+	conn, _, err := rc.Hijack()
+	if err != nil {
+		return // HTTP/2 can't be hijacked; the response just ends early
+	}
+	conn.Close()
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxMockDelay caps X-Mock-Delay-Ms so a typo can't hang a test run.
+const maxMockDelay = 60 * time.Second
+
+// mockError is a canned Etsy error X-Mock-Error can ask for.
+type mockError struct {
+	status int
+	msg    string
+}
+
+var mockErrors = map[string]mockError{
+	"invalid_request":    {http.StatusBadRequest, "Invalid request"},
+	"invalid_api_key":    {http.StatusUnauthorized, "Invalid API key: keystring not recognized"},
+	"invalid_token":      {http.StatusUnauthorized, "Invalid or expired OAuth access token"},
+	"banned_app":         {http.StatusForbidden, "This API key has been revoked or the application has been banned"},
+	"insufficient_scope": {http.StatusForbidden, "The OAuth access token does not have the scope this endpoint requires"},
+	"not_found":          {http.StatusNotFound, "Resource not found"},
+	"rate_limited":       {http.StatusTooManyRequests, "Rate limit exceeded"},
+	"server_error":       {http.StatusInternalServerError, "Internal server error"},
+	"unavailable":        {http.StatusServiceUnavailable, "Service unavailable"},
+}
+
+// MockControl lets a single request force its own response, so parallel
+// tests don't have to share /admin/faults rules:
+//
+//	X-Mock-Delay-Ms: 1500       wait before answering
+//	X-Mock-Status: 429          answer with this 4xx/5xx status
+//	X-Mock-Error: invalid_token answer with a canned Etsy error
+//
+// X-Mock-Status overrides the status of X-Mock-Error. A request with only a
+// delay goes on to the handler afterwards. Paths under /admin/ are ignored.
+func MockControl(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/admin/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var delay time.Duration
+		if v := r.Header.Get("X-Mock-Delay-Ms"); v != "" {
+			ms, err := strconv.Atoi(v)
+			if err != nil || ms < 0 || time.Duration(ms)*time.Millisecond > maxMockDelay {
+				writeError(w, http.StatusBadRequest, "X-Mock-Delay-Ms must be between 0 and "+strconv.Itoa(int(maxMockDelay/time.Millisecond)))
+				return
+			}
+			delay = time.Duration(ms) * time.Millisecond
+		}
+
+		var forced *mockError
+		if v := r.Header.Get("X-Mock-Error"); v != "" {
+			e, ok := mockErrors[v]
+			if !ok {
+				writeError(w, http.StatusBadRequest, "X-Mock-Error must be one of: "+strings.Join(mockErrorNames(), ", "))
+				return
+			}
+			forced = &e
+		}
+		if v := r.Header.Get("X-Mock-Status"); v != "" {
+			status, err := strconv.Atoi(v)
+			if err != nil || status < 400 || status > 599 {
+				writeError(w, http.StatusBadRequest, "X-Mock-Status must be a status between 400 and 599")
+				return
+			}
+			if forced == nil {
+				forced = &mockError{msg: http.StatusText(status)}
+			}
+			forced.status = status
+		}
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if forced == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if forced.status == http.StatusTooManyRequests {
+			w.Header().Set("retry-after", "1")
+		}
+		writeError(w, forced.status, forced.msg)
+	})
+}
+
+func mockErrorNames() []string {
+	names := make([]string, 0, len(mockErrors))
+	for name := range mockErrors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
+)
+
+// Etsy's default limits for an API key.
+const (
+	DefaultPerSecond = 10
+	DefaultPerDay    = 10000
+)
+
+// rateBucket is a pair of token buckets for one keystring. Each starts full
+// and refills continuously at its limit per second or per day. The
+// per-second bucket follows the wall clock and the per-day bucket the
+// virtual clock, so a frozen clock can't stall a throttled key for good and
+// advancing the clock by a day refills the daily quota.
+type rateBucket struct {
+	second, day               float64
+	secondUpdated, dayUpdated time.Time
+}
+
+// rateStatus is the outcome of taking a token for one request.
+type rateStatus struct {
+	perSecond, perDay             int
+	remainingSecond, remainingDay int
+	allowed                       bool
+	retryAfter                    int // seconds until both buckets have a token again
+}
+
+// limits returns the per-second and per-day limits of a keystring, falling
+// back to the defaults for unregistered keys and zero fields.
+func (ks *APIKeyStore) limits(keystring string) (int, int) {
+	perSecond, perDay := DefaultPerSecond, DefaultPerDay
+	if entry, ok := ks.keys[keystring]; ok {
+		if entry.PerSecond > 0 {
+			perSecond = entry.PerSecond
+		}
+		if entry.PerDay > 0 {
+			perDay = entry.PerDay
+		}
+	}
+	return perSecond, perDay
+}
+
+// take refills the keystring's per-second bucket up to wall and its per-day
+// bucket up to virtual, then spends a token from each if both have one.
+func (ks *APIKeyStore) take(keystring string, wall, virtual time.Time) rateStatus {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	perSecond, perDay := ks.limits(keystring)
+	b, ok := ks.buckets[keystring]
+	if !ok {
+		b = &rateBucket{
+			second:        float64(perSecond),
+			day:           float64(perDay),
+			secondUpdated: wall,
+			dayUpdated:    virtual,
+		}
+		ks.buckets[keystring] = b
+	}
+	if elapsed := wall.Sub(b.secondUpdated).Seconds(); elapsed > 0 {
+		b.second = math.Min(float64(perSecond), b.second+elapsed*float64(perSecond))
+	}
+	if elapsed := virtual.Sub(b.dayUpdated).Seconds(); elapsed > 0 {
+		b.day = math.Min(float64(perDay), b.day+elapsed*float64(perDay)/86400)
+	}
+	// Moving the virtual clock back restarts the day bucket's refill from
+	// the new time rather than waiting for the clock to catch up.
+	b.secondUpdated, b.dayUpdated = wall, virtual
+
+	st := rateStatus{perSecond: perSecond, perDay: perDay}
+	if b.second >= 1 && b.day >= 1 {
+		b.second--
+		b.day--
+		st.allowed = true
+	} else {
+		wait := 0.0
+		if b.second < 1 {
+			wait = (1 - b.second) / float64(perSecond)
+		}
+		if b.day < 1 {
+			wait = math.Max(wait, (1-b.day)*86400/float64(perDay))
+		}
+		st.retryAfter = int(math.Ceil(wait))
+	}
+	st.remainingSecond = int(b.second)
+	st.remainingDay = int(b.day)
+	return st
+}
+
+// RateLimit enforces the per-second and per-day limits of the request's API
+// key and reports them in Etsy's x-limit-* and x-remaining-* headers. A
+// request that finds either bucket empty gets a 429 with retry-after.
+// Requests without an API key, and admin endpoints, are not limited.
+func RateLimit(keyStore *APIKeyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keystring, _ := r.Context().Value(ContextKeystring).(string)
+			if keystring == "" {
+				// With -no-auth nothing validated the key, but it still
+				// names the bucket.
+				keystring, _, _ = strings.Cut(r.Header.Get("x-api-key"), ":")
+			}
+			if keystring == "" || strings.HasPrefix(r.URL.Path, "/admin/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			st := keyStore.take(keystring, time.Now(), clock.Now())
+			w.Header().Set("x-limit-per-second", strconv.Itoa(st.perSecond))
+			w.Header().Set("x-remaining-this-second", strconv.Itoa(st.remainingSecond))
+			w.Header().Set("x-limit-per-day", strconv.Itoa(st.perDay))
+			w.Header().Set("x-remaining-today", strconv.Itoa(st.remainingDay))
+			if !st.allowed {
+				w.Header().Set("retry-after", strconv.Itoa(st.retryAfter))
+				writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/openapi"
+)
+
+// maxViolationsInHeader caps how many violations X-Mock-Contract-Violations
+// lists; the full set is always logged.
+const maxViolationsInHeader = 10
+
+// VerifyResponses checks each JSON response body against the schema its
+// operation documents for that status. Drift is logged and listed in the
+// X-Mock-Contract-Violations header so tests can assert the mock stays
+// faithful to the real contract. Responses the spec doesn't describe pass
+// through untouched.
+func VerifyResponses(spec *openapi.Spec) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, _ := spec.Match(r.Method, r.URL.Path)
+			if op == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if schema := op.ResponseSchema(rec.status); schema != nil && rec.body.Len() > 0 {
+				var v interface{}
+				var violations []string
+				if err := json.Unmarshal(rec.body.Bytes(), &v); err != nil {
+					violations = []string{"Response body is not valid JSON"}
+				} else {
+					violations = spec.CheckResponse(schema, v)
+				}
+				for _, msg := range violations {
+					log.Printf("contract: %s %s (%s %d): %s", r.Method, r.URL.Path, op.OperationID, rec.status, msg)
+				}
+				if len(violations) > maxViolationsInHeader {
+					violations = append(violations[:maxViolationsInHeader], "...")
+				}
+				if len(violations) > 0 {
+					w.Header().Set("X-Mock-Contract-Violations", strings.Join(violations, "; "))
+				}
+			}
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+		})
+	}
+}
+
+// responseRecorder holds back a handler's response so it can be inspected
+// before any of it is sent.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	rr.status = status
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	return rr.body.Write(b)
+}
@@ -18,6 +18,12 @@ type TokenResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// OAuth2 error response (RFC 6749 section 5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
 // Scopes check response
 type ScopesResponse struct {
 	Scopes []string `json:"scopes"`
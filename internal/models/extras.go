@@ -40,14 +40,19 @@ type ShopHolidayPreferences struct {
 	VacationMessage *string `json:"vacation_message"`
 }
 
-// ReadinessStateDefinition represents a processing/readiness state.
+// ReadinessStateDefinition represents a shop's processing profile, which
+// inventory offerings refer to by readiness_state_id.
 type ReadinessStateDefinition struct {
-	ReadinessStateID    int64  `json:"readiness_state_id"`
-	Name                string `json:"name"`
-	Label               string `json:"label"`
-	ProcessingTimeUnit  string `json:"processing_time_unit"`
-	ProcessingMin       int    `json:"processing_min"`
-	ProcessingMax       int    `json:"processing_max"`
+	ShopID                     int64  `json:"shop_id"`
+	ReadinessStateID           int64  `json:"readiness_state_id"`
+	ReadinessState             string `json:"readiness_state"`
+	MinProcessingDays          int    `json:"min_processing_days"`
+	MaxProcessingDays          int    `json:"max_processing_days"`
+	ProcessingDaysDisplayLabel string `json:"processing_days_display_label"`
+	// ProcessingTimeUnit is the processing_time_unit the times were last
+	// given in, "days" or "weeks". Etsy doesn't return it; the mock keeps it
+	// so updates can read stored times back in the same unit.
+	ProcessingTimeUnit string `json:"processing_time_unit,omitempty"`
 }
 
 // ShippingCarrier represents a supported shipping carrier.
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type ShopListing struct {
 	ListingID                  int64    `json:"listing_id"`
 	UserID                     int64    `json:"user_id"`
@@ -107,6 +113,7 @@ type ListingInventory struct {
 	PriceOnProperty      []int                     `json:"price_on_property"`
 	QuantityOnProperty   []int                     `json:"quantity_on_property"`
 	SKUOnProperty        []int                     `json:"sku_on_property"`
+	ReadinessStateOnProperty []int                 `json:"readiness_state_on_property"`
 }
 
 type ListingInventoryProduct struct {
@@ -117,12 +124,24 @@ type ListingInventoryProduct struct {
 	PropertyValues []ListingPropertyValue          `json:"property_values"`
 }
 
+// Combination identifies a product by its property values, independent of
+// the order they are listed in.
+func (p ListingInventoryProduct) Combination() string {
+	pairs := make([]string, 0, len(p.PropertyValues))
+	for _, pv := range p.PropertyValues {
+		pairs = append(pairs, fmt.Sprintf("%d:%v", pv.PropertyID, pv.ValueIDs))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
 type ListingInventoryProductOffering struct {
-	OfferingID int64 `json:"offering_id"`
-	Quantity   int   `json:"quantity"`
-	IsEnabled  bool  `json:"is_enabled"`
-	IsDeleted  bool  `json:"is_deleted"`
-	Price      Money `json:"price"`
+	OfferingID       int64  `json:"offering_id"`
+	Quantity         int    `json:"quantity"`
+	IsEnabled        bool   `json:"is_enabled"`
+	IsDeleted        bool   `json:"is_deleted"`
+	Price            Money  `json:"price"`
+	ReadinessStateID *int64 `json:"readiness_state_id"`
 }
 
 type ListingPropertyValue struct {
@@ -144,6 +163,7 @@ type CreateListingRequest struct {
 	TaxonomyID         int      `json:"taxonomy_id"`
 	ShippingProfileID  *int64   `json:"shipping_profile_id"`
 	ReturnPolicyID     *int64   `json:"return_policy_id"`
+	ShopSectionID      *int64   `json:"shop_section_id"`
 	Materials          []string `json:"materials"`
 	Tags               []string `json:"tags"`
 	Styles             []string `json:"styles"`
@@ -169,6 +189,7 @@ type UpdateListingRequest struct {
 	TaxonomyID         *int      `json:"taxonomy_id"`
 	ShippingProfileID  *int64    `json:"shipping_profile_id"`
 	ReturnPolicyID     *int64    `json:"return_policy_id"`
+	ShopSectionID      *int64    `json:"shop_section_id"` // 0 removes the listing from its section
 	Materials          []string  `json:"materials"`
 	Tags               []string  `json:"tags"`
 	State              *string   `json:"state"`
@@ -180,3 +201,30 @@ type UpdateListingRequest struct {
 	ItemHeight         *float32  `json:"item_height"`
 	ItemDimensionsUnit *string   `json:"item_dimensions_unit"`
 }
+
+type UpdateListingInventoryRequest struct {
+	Products                 []UpdateListingInventoryProduct `json:"products"`
+	PriceOnProperty          []int                           `json:"price_on_property"`
+	QuantityOnProperty       []int                           `json:"quantity_on_property"`
+	SKUOnProperty            []int                           `json:"sku_on_property"`
+	ReadinessStateOnProperty []int                           `json:"readiness_state_on_property"`
+}
+
+type UpdateListingInventoryProduct struct {
+	SKU            *string                          `json:"sku"`
+	PropertyValues []ListingPropertyValue           `json:"property_values"`
+	Offerings      []UpdateListingInventoryOffering `json:"offerings"`
+}
+
+type UpdateListingInventoryOffering struct {
+	Price            float64 `json:"price"`
+	Quantity         int     `json:"quantity"`
+	IsEnabled        bool    `json:"is_enabled"`
+	ReadinessStateID *int64  `json:"readiness_state_id"`
+}
+
+type UpdateListingPropertyRequest struct {
+	ValueIDs []int    `json:"value_ids"`
+	Values   []string `json:"values"`
+	ScaleID  *int64   `json:"scale_id"`
+}
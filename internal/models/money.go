@@ -1,5 +1,7 @@
 package models
 
+import "math"
+
 type Money struct {
 	Amount       int    `json:"amount"`
 	Divisor      int    `json:"divisor"`
@@ -9,3 +11,9 @@ type Money struct {
 func USD(cents int) Money {
 	return Money{Amount: cents, Divisor: 100, CurrencyCode: "USD"}
 }
+
+// NewMoney converts a decimal amount, as sent in request bodies, to Money in
+// the given currency.
+func NewMoney(amount float64, currencyCode string) Money {
+	return Money{Amount: int(math.Round(amount * 100)), Divisor: 100, CurrencyCode: currencyCode}
+}
@@ -42,3 +42,35 @@ type ShopShippingProfileUpgrade struct {
 	MinDeliveryDays   *int    `json:"min_delivery_days"`
 	MaxDeliveryDays   *int    `json:"max_delivery_days"`
 }
+
+type UpdateShippingProfileRequest struct {
+	Title            *string `json:"title"`
+	OriginCountryISO *string `json:"origin_country_iso"`
+	OriginPostalCode *string `json:"origin_postal_code"`
+}
+
+// ShippingProfileDestinationRequest is the body of both create and update;
+// on update, nil fields keep their current value.
+type ShippingProfileDestinationRequest struct {
+	PrimaryCost           *float64 `json:"primary_cost"`
+	SecondaryCost         *float64 `json:"secondary_cost"`
+	DestinationCountryISO *string  `json:"destination_country_iso"`
+	DestinationRegion     *string  `json:"destination_region"`
+	ShippingCarrierID     *int     `json:"shipping_carrier_id"`
+	MailClass             *string  `json:"mail_class"`
+	MinDeliveryDays       *int     `json:"min_delivery_days"`
+	MaxDeliveryDays       *int     `json:"max_delivery_days"`
+}
+
+// ShippingProfileUpgradeRequest is the body of both create and update; on
+// update, nil fields keep their current value.
+type ShippingProfileUpgradeRequest struct {
+	Type              *int     `json:"type"`
+	UpgradeName       *string  `json:"upgrade_name"`
+	Price             *float64 `json:"price"`
+	SecondaryPrice    *float64 `json:"secondary_price"`
+	ShippingCarrierID *int     `json:"shipping_carrier_id"`
+	MailClass         *string  `json:"mail_class"`
+	MinDeliveryDays   *int     `json:"min_delivery_days"`
+	MaxDeliveryDays   *int     `json:"max_delivery_days"`
+}
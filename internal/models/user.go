@@ -8,6 +8,13 @@ type User struct {
 	ImageURL75x75 *string `json:"image_url_75x75"`
 }
 
+// Self is the requesting user as returned by getMe. ShopID is omitted when
+// the user has no shop.
+type Self struct {
+	UserID int64  `json:"user_id"`
+	ShopID *int64 `json:"shop_id,omitempty"`
+}
+
 type UserAddress struct {
 	UserAddressID           int64   `json:"user_address_id"`
 	UserID                  int64   `json:"user_id"`
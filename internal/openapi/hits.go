@@ -0,0 +1,36 @@
+package openapi
+
+import "sync"
+
+// Hits counts requests per operationId.
+type Hits struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func NewHits() *Hits {
+	return &Hits{counts: make(map[string]int)}
+}
+
+func (h *Hits) Record(operationID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[operationID]++
+}
+
+// Counts returns a copy of the counts so far.
+func (h *Hits) Counts() map[string]int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[string]int, len(h.counts))
+	for id, n := range h.counts {
+		out[id] = n
+	}
+	return out
+}
+
+func (h *Hits) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts = make(map[string]int)
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of an OpenAPI schema object the mock understands.
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Enum       []interface{}      `json:"enum"`
+	Minimum    *float64           `json:"minimum"`
+	Maximum    *float64           `json:"maximum"`
+	Nullable   bool               `json:"nullable"`
+	Items      *Schema            `json:"items"`
+	Properties map[string]*Schema `json:"properties"`
+	Required   []string           `json:"required"`
+	AllOf      []*Schema          `json:"allOf"`
+	OneOf      []*Schema          `json:"oneOf"`
+	AnyOf      []*Schema          `json:"anyOf"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Content map[string]MediaType `json:"content"`
+}
+
+// Operation is one method on one path of the spec.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters"`
+	RequestBody *RequestBody          `json:"requestBody"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+
+	Method   string `json:"-"`
+	Path     string `json:"-"`
+	segments []string
+}
+
+// Spec is a loaded OpenAPI document.
+type Spec struct {
+	Operations []*Operation
+	Schemas    map[string]*Schema
+}
+
+// Load reads and indexes the OpenAPI document at path.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]*Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	spec := &Spec{Schemas: doc.Components.Schemas}
+	for p, item := range doc.Paths {
+		for method, raw := range item {
+			m := strings.ToUpper(method)
+			switch m {
+			case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				continue
+			}
+			var op Operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("parse %s %s: %w", m, p, err)
+			}
+			op.Method = m
+			op.Path = p
+			op.segments = strings.Split(p, "/")
+			spec.Operations = append(spec.Operations, &op)
+		}
+	}
+	return spec, nil
+}
+
+// Scopes returns the OAuth2 scopes the operation lists, or nil if it only
+// needs an API key.
+func (op *Operation) Scopes() []string {
+	var scopes []string
+	for _, req := range op.Security {
+		scopes = append(scopes, req["oauth2"]...)
+	}
+	return scopes
+}
+
+// Match finds the operation for a request and returns it with its path
+// parameter values. Literal segments win over templated ones, so
+// /users/me is getMe rather than getUser. A templated segment only matches
+// a value of its parameter's type, which lets the mock's own routes that
+// share a prefix with the spec (such as /listings/featured) fall through
+// unmatched.
+func (s *Spec) Match(method, path string) (*Operation, map[string]string) {
+	segments := strings.Split(path, "/")
+	var best *Operation
+	var bestParams map[string]string
+	bestLiterals := -1
+	for _, op := range s.Operations {
+		if op.Method != method || len(op.segments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		literals := 0
+		matched := true
+		for i, seg := range op.segments {
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				name := seg[1 : len(seg)-1]
+				if segments[i] == "" || !op.pathValueFits(name, segments[i]) {
+					matched = false
+					break
+				}
+				params[name] = segments[i]
+				continue
+			}
+			if seg != segments[i] {
+				matched = false
+				break
+			}
+			literals++
+		}
+		if matched && literals > bestLiterals {
+			best, bestParams, bestLiterals = op, params, literals
+		}
+	}
+	return best, bestParams
+}
+
+// pathValueFits reports whether value parses as the type of the named path
+// parameter. Range checks are left to validation.
+func (op *Operation) pathValueFits(name, value string) bool {
+	for _, p := range op.Parameters {
+		if p.In == "path" && p.Name == name && p.Schema != nil {
+			_, err := coerce(p.Schema, []string{value})
+			return err == nil
+		}
+	}
+	return true
+}
+
+// Resolve follows $ref until it reaches a concrete schema.
+func (s *Spec) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// ResponseSchema returns the JSON schema documented for status, or nil when
+// the operation documents no JSON body for it.
+func (op *Operation) ResponseSchema(status int) *Schema {
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		return nil
+	}
+	return resp.Content["application/json"].Schema
+}
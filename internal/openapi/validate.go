@@ -0,0 +1,336 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// typeNames phrase a schema type the way violation messages use it.
+var typeNames = map[string]string{
+	"integer": "an integer",
+	"number":  "a number",
+	"boolean": "a boolean",
+	"string":  "a string",
+	"array":   "an array",
+	"object":  "an object",
+}
+
+// ValidateRequest checks the path, query and body of r against op and
+// returns the first violation, or "" when the request is valid. body is the
+// already-read request body.
+func (s *Spec) ValidateRequest(op *Operation, r *http.Request, pathParams map[string]string, body []byte) string {
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		schema := s.Resolve(p.Schema)
+		if schema == nil {
+			continue
+		}
+		var raw []string
+		switch p.In {
+		case "path":
+			raw = []string{pathParams[p.Name]}
+		case "query":
+			raw = nonEmpty(query[p.Name])
+		default:
+			continue
+		}
+		if len(raw) == 0 {
+			if p.Required {
+				return "Missing required parameter: " + p.Name
+			}
+			continue
+		}
+		v, err := coerce(schema, raw)
+		if err != nil {
+			return fmt.Sprintf("Parameter %s must be %s", p.Name, describe(schema))
+		}
+		if errs := s.Check(schema, v, p.Name); len(errs) > 0 {
+			return errs[0]
+		}
+	}
+	if op.RequestBody != nil {
+		return s.validateBody(op.RequestBody, r.Header.Get("Content-Type"), body)
+	}
+	return ""
+}
+
+// validateBody checks a JSON or form-encoded body against the operation's
+// body schema. Multipart uploads are not checked.
+func (s *Spec) validateBody(rb *RequestBody, contentType string, body []byte) string {
+	var schema *Schema
+	for ct, mt := range rb.Content {
+		if ct == "application/json" || ct == "application/x-www-form-urlencoded" {
+			schema = s.Resolve(mt.Schema)
+		}
+	}
+	if schema == nil || strings.HasPrefix(contentType, "multipart/") {
+		return ""
+	}
+
+	var v interface{}
+	trimmed := strings.TrimSpace(string(body))
+	switch {
+	case trimmed == "":
+		v = map[string]interface{}{}
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(trimmed)
+		if err != nil {
+			return "Invalid request body"
+		}
+		obj := make(map[string]interface{})
+		for key, raw := range form {
+			raw = nonEmpty(raw)
+			prop := s.Resolve(schema.Properties[key])
+			if prop == nil || len(raw) == 0 {
+				continue
+			}
+			fv, err := coerce(prop, raw)
+			if err != nil {
+				return fmt.Sprintf("Parameter %s must be %s", key, describe(prop))
+			}
+			obj[key] = fv
+		}
+		v = obj
+	default:
+		if err := json.Unmarshal([]byte(trimmed), &v); err != nil {
+			return "Invalid request body"
+		}
+	}
+	if errs := s.Check(schema, v, ""); len(errs) > 0 {
+		return errs[0]
+	}
+	return ""
+}
+
+// Check validates a decoded request value against schema and returns every
+// violation found. at names the value in messages; nested values are named
+// like products[0].offerings[1].price.
+func (s *Spec) Check(schema *Schema, v interface{}, at string) []string {
+	return checker{spec: s, noun: "Parameter"}.check(schema, v, at)
+}
+
+// CheckResponse is Check for response bodies. It is stricter: every
+// non-nullable property the schema documents must be present, and properties
+// it doesn't document are reported as unexpected.
+func (s *Spec) CheckResponse(schema *Schema, v interface{}) []string {
+	return checker{spec: s, noun: "Field", strict: true}.check(schema, v, "")
+}
+
+type checker struct {
+	spec   *Spec
+	noun   string // what messages call a value: "Parameter" or "Field"
+	strict bool
+}
+
+func (c checker) check(schema *Schema, v interface{}, at string) []string {
+	schema = c.spec.Resolve(schema)
+	if schema == nil || (v == nil && schema.Nullable) {
+		return nil
+	}
+	var errs []string
+	for _, sub := range schema.AllOf {
+		errs = append(errs, c.check(sub, v, at)...)
+	}
+	if alts := append(append([]*Schema{}, schema.OneOf...), schema.AnyOf...); len(alts) > 0 {
+		var first []string
+		for i, sub := range alts {
+			subErrs := c.check(sub, v, at)
+			if len(subErrs) == 0 {
+				first = nil
+				break
+			}
+			if i == 0 {
+				first = subErrs
+			}
+		}
+		errs = append(errs, first...)
+	}
+
+	if v == nil {
+		if schema.Type != "" {
+			errs = append(errs, fmt.Sprintf("%s %s must not be null", c.noun, name(at)))
+		}
+		return errs
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok || (schema.Type == "integer" && n != math.Trunc(n)) {
+			return append(errs, fmt.Sprintf("%s %s must be %s", c.noun, name(at), typeNames[schema.Type]))
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			errs = append(errs, fmt.Sprintf("%s %s must be at least %s", c.noun, name(at), formatNumber(*schema.Minimum)))
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			errs = append(errs, fmt.Sprintf("%s %s must be at most %s", c.noun, name(at), formatNumber(*schema.Maximum)))
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return append(errs, fmt.Sprintf("%s %s must be a string", c.noun, name(at)))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return append(errs, fmt.Sprintf("%s %s must be a boolean", c.noun, name(at)))
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s %s must be an array", c.noun, name(at)))
+		}
+		for i, item := range items {
+			errs = append(errs, c.check(schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return append(errs, fmt.Sprintf("%s %s must be an object", c.noun, name(at)))
+		}
+		keys := make([]string, 0, len(schema.Properties))
+		for key := range schema.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		if c.strict {
+			// Nullable properties may be left out, the way Etsy omits
+			// associations that weren't requested.
+			for _, key := range keys {
+				if _, present := obj[key]; !present && !c.nullable(schema.Properties[key]) {
+					errs = append(errs, "Missing field: "+join(at, key))
+				}
+			}
+		} else {
+			for _, req := range schema.Required {
+				if _, present := obj[req]; !present {
+					errs = append(errs, "Missing required parameter: "+join(at, req))
+				}
+			}
+		}
+		for _, key := range keys {
+			if pv, present := obj[key]; present {
+				errs = append(errs, c.check(schema.Properties[key], pv, join(at, key))...)
+			}
+		}
+		if c.strict && len(schema.Properties) > 0 {
+			var extra []string
+			for key := range obj {
+				if _, documented := schema.Properties[key]; !documented {
+					extra = append(extra, key)
+				}
+			}
+			sort.Strings(extra)
+			for _, key := range extra {
+				errs = append(errs, "Unexpected field: "+join(at, key))
+			}
+		}
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, v) {
+		allowed := make([]string, len(schema.Enum))
+		for i, e := range schema.Enum {
+			allowed[i] = fmt.Sprint(e)
+		}
+		errs = append(errs, fmt.Sprintf("%s %s must be one of: %s", c.noun, name(at), strings.Join(allowed, ", ")))
+	}
+	return errs
+}
+
+func (c checker) nullable(schema *Schema) bool {
+	if schema == nil {
+		return false
+	}
+	if schema.Nullable {
+		return true
+	}
+	resolved := c.spec.Resolve(schema)
+	return resolved != nil && resolved.Nullable
+}
+
+// coerce converts raw query, path or form values to the JSON value schema
+// describes. Arrays accept both repeated keys and comma-separated lists.
+func coerce(schema *Schema, raw []string) (interface{}, error) {
+	switch schema.Type {
+	case "array":
+		items := []interface{}{}
+		itemSchema := schema.Items
+		if itemSchema == nil {
+			itemSchema = &Schema{}
+		}
+		for _, r := range raw {
+			for _, part := range strings.Split(r, ",") {
+				part = strings.TrimSpace(part)
+				if part == "" {
+					continue
+				}
+				v, err := coerce(itemSchema, []string{part})
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+			}
+		}
+		return items, nil
+	case "integer":
+		n, err := strconv.ParseInt(raw[0], 10, 64)
+		return float64(n), err
+	case "number":
+		return strconv.ParseFloat(raw[0], 64)
+	case "boolean":
+		return strconv.ParseBool(raw[0])
+	default:
+		return raw[0], nil
+	}
+}
+
+// describe phrases the type a raw value failed to parse as.
+func describe(schema *Schema) string {
+	if schema.Type == "array" && schema.Items != nil && schema.Items.Type != "" {
+		return "a comma-separated list of " + schema.Items.Type + "s"
+	}
+	return typeNames[schema.Type]
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func nonEmpty(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+func join(at, key string) string {
+	if at == "" {
+		return key
+	}
+	return at + "." + key
+}
+
+// name is how a value is referred to in messages; the body itself has no
+// name of its own.
+func name(at string) string {
+	if at == "" {
+		return "body"
+	}
+	return at
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 	"github.com/vlah-software-house/etsy-mock-api/internal/store"
 )
@@ -310,7 +311,7 @@ var shopAnnouncements = []string{
 
 func GenerateFromConfig(s *store.Store, cfg SeedConfig) {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	now := time.Now().Unix()
+	now := clock.Now().Unix()
 	ago := func(days int) int64 { return now - int64(days*86400) }
 
 	// Taxonomy (same as hardcoded seed — always needed)
@@ -418,6 +419,16 @@ func GenerateFromConfig(s *store.Store, cfg SeedConfig) {
 			ReturnPolicyID: rpID, ShopID: shopID, AcceptsReturns: true, AcceptsExchanges: r.Float64() > 0.3, ReturnDeadline: &deadline,
 		}
 
+		// Readiness state definitions
+		for _, d := range []models.ReadinessStateDefinition{
+			{ReadinessState: "ready_to_ship", MinProcessingDays: 1, MaxProcessingDays: 3, ProcessingDaysDisplayLabel: "1-3 days", ProcessingTimeUnit: "days"},
+			{ReadinessState: "made_to_order", MinProcessingDays: 3, MaxProcessingDays: 7, ProcessingDaysDisplayLabel: "3-7 days", ProcessingTimeUnit: "days"},
+		} {
+			d.ShopID = shopID
+			d.ReadinessStateID = getID()
+			s.ReadinessStateDefinitions[d.ReadinessStateID] = &d
+		}
+
 		// Sections
 		sectionNames := []string{cat.Items[0], cat.Items[len(cat.Items)/3], cat.Items[len(cat.Items)*2/3]}
 		var sectionIDs []int64
@@ -703,6 +714,9 @@ func loadTaxonomy(s *store.Store) {
 	}
 	s.TaxonomyProperties[1207] = []models.BuyerTaxonomyNodeProperty{
 		{PropertyID: 100, Name: "material", DisplayName: "Material", IsRequired: false, SupportsAttributes: true, SupportsVariations: false, IsMultivalued: true, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 1001, Name: "Sterling Silver"}, {ValueID: 1002, Name: "Gold"}, {ValueID: 1003, Name: "Rose Gold"}, {ValueID: 1004, Name: "Brass"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
+		{PropertyID: 102, Name: "primary_color", DisplayName: "Primary color", IsRequired: false, SupportsAttributes: true, SupportsVariations: true, IsMultivalued: false, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 4001, Name: "Black"}, {ValueID: 4002, Name: "Blue"}, {ValueID: 4003, Name: "Gold"}, {ValueID: 4004, Name: "Silver"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
+		{PropertyID: 103, Name: "occasion", DisplayName: "Occasion", IsRequired: false, SupportsAttributes: true, SupportsVariations: false, IsMultivalued: true, MaxValuesAllowed: intPtr(3), PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 4101, Name: "Anniversary"}, {ValueID: 4102, Name: "Birthday"}, {ValueID: 4103, Name: "Wedding"}, {ValueID: 4104, Name: "Graduation"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
+		{PropertyID: 104, Name: "chain_width", DisplayName: "Chain Width", IsRequired: false, SupportsAttributes: true, SupportsVariations: true, IsMultivalued: false, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 4201, Name: "1 mm", ScaleID: int64Ptr(1)}, {ValueID: 4202, Name: "2 mm", ScaleID: int64Ptr(1)}, {ValueID: 4203, Name: "1/16 in", ScaleID: int64Ptr(2)}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{{ScaleID: 1, DisplayName: "Millimeters", Description: ""}, {ScaleID: 2, DisplayName: "Inches", Description: ""}}},
 	}
 	s.TaxonomyProperties[562] = []models.BuyerTaxonomyNodeProperty{
 		{PropertyID: 200, Name: "material", DisplayName: "Wood Type", IsRequired: false, SupportsAttributes: true, SupportsVariations: false, IsMultivalued: true, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 3001, Name: "Walnut"}, {ValueID: 3002, Name: "Maple"}, {ValueID: 3003, Name: "Cherry"}, {ValueID: 3004, Name: "Oak"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
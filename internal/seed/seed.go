@@ -2,8 +2,8 @@ package seed
 
 import (
 	"fmt"
-	"time"
 
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 	"github.com/vlah-software-house/etsy-mock-api/internal/store"
 )
@@ -15,7 +15,7 @@ func float32Ptr(f float32) *float32 { return &f }
 func boolPtr(b bool) *bool    { return &b }
 
 func Load(s *store.Store) {
-	now := time.Now().Unix()
+	now := clock.Now().Unix()
 	ago := func(days int) int64 { return now - int64(days*86400) }
 
 	// --- Users ---
@@ -120,6 +120,12 @@ func Load(s *store.Store) {
 	s.ShopReturnPolicies[4001] = &models.ShopReturnPolicy{ReturnPolicyID: 4001, ShopID: 5001, AcceptsReturns: true, AcceptsExchanges: true, ReturnDeadline: &deadline30}
 	s.ShopReturnPolicies[4002] = &models.ShopReturnPolicy{ReturnPolicyID: 4002, ShopID: 5002, AcceptsReturns: true, AcceptsExchanges: false, ReturnDeadline: &deadline30}
 
+	// --- Readiness State Definitions (processing profiles) ---
+	s.ReadinessStateDefinitions[13001] = &models.ReadinessStateDefinition{ShopID: 5001, ReadinessStateID: 13001, ReadinessState: "ready_to_ship", MinProcessingDays: 1, MaxProcessingDays: 3, ProcessingDaysDisplayLabel: "1-3 days", ProcessingTimeUnit: "days"}
+	s.ReadinessStateDefinitions[13002] = &models.ReadinessStateDefinition{ShopID: 5001, ReadinessStateID: 13002, ReadinessState: "made_to_order", MinProcessingDays: 3, MaxProcessingDays: 7, ProcessingDaysDisplayLabel: "3-7 days", ProcessingTimeUnit: "days"}
+	s.ReadinessStateDefinitions[13003] = &models.ReadinessStateDefinition{ShopID: 5002, ReadinessStateID: 13003, ReadinessState: "ready_to_ship", MinProcessingDays: 1, MaxProcessingDays: 2, ProcessingDaysDisplayLabel: "1-2 days", ProcessingTimeUnit: "days"}
+	s.ReadinessStateDefinitions[13004] = &models.ReadinessStateDefinition{ShopID: 5002, ReadinessStateID: 13004, ReadinessState: "made_to_order", MinProcessingDays: 5, MaxProcessingDays: 10, ProcessingDaysDisplayLabel: "5-10 days", ProcessingTimeUnit: "days"}
+
 	// --- Shop Sections ---
 	s.ShopSections[6001] = &models.ShopSection{ShopSectionID: 6001, Title: "Necklaces", Rank: 1, UserID: 5001, ActiveListingCount: 4}
 	s.ShopSections[6002] = &models.ShopSection{ShopSectionID: 6002, Title: "Earrings", Rank: 2, UserID: 5001, ActiveListingCount: 3}
@@ -370,6 +376,9 @@ func Load(s *store.Store) {
 	s.TaxonomyProperties[1207] = []models.BuyerTaxonomyNodeProperty{
 		{PropertyID: 100, Name: "material", DisplayName: "Material", IsRequired: false, SupportsAttributes: true, SupportsVariations: false, IsMultivalued: true, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 1001, Name: "Sterling Silver"}, {ValueID: 1002, Name: "Gold"}, {ValueID: 1003, Name: "Rose Gold"}, {ValueID: 1004, Name: "Brass"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
 		{PropertyID: 101, Name: "length", DisplayName: "Chain Length", IsRequired: false, SupportsAttributes: true, SupportsVariations: true, IsMultivalued: false, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 2001, Name: "14 inches"}, {ValueID: 2002, Name: "16 inches"}, {ValueID: 2003, Name: "18 inches"}, {ValueID: 2004, Name: "20 inches"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
+		{PropertyID: 102, Name: "primary_color", DisplayName: "Primary color", IsRequired: false, SupportsAttributes: true, SupportsVariations: true, IsMultivalued: false, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 4001, Name: "Black"}, {ValueID: 4002, Name: "Blue"}, {ValueID: 4003, Name: "Gold"}, {ValueID: 4004, Name: "Silver"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
+		{PropertyID: 103, Name: "occasion", DisplayName: "Occasion", IsRequired: false, SupportsAttributes: true, SupportsVariations: false, IsMultivalued: true, MaxValuesAllowed: intPtr(3), PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 4101, Name: "Anniversary"}, {ValueID: 4102, Name: "Birthday"}, {ValueID: 4103, Name: "Wedding"}, {ValueID: 4104, Name: "Graduation"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
+		{PropertyID: 104, Name: "chain_width", DisplayName: "Chain Width", IsRequired: false, SupportsAttributes: true, SupportsVariations: true, IsMultivalued: false, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 4201, Name: "1 mm", ScaleID: int64Ptr(1)}, {ValueID: 4202, Name: "2 mm", ScaleID: int64Ptr(1)}, {ValueID: 4203, Name: "1/16 in", ScaleID: int64Ptr(2)}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{{ScaleID: 1, DisplayName: "Millimeters", Description: ""}, {ScaleID: 2, DisplayName: "Inches", Description: ""}}},
 	}
 	s.TaxonomyProperties[562] = []models.BuyerTaxonomyNodeProperty{
 		{PropertyID: 200, Name: "material", DisplayName: "Wood Type", IsRequired: false, SupportsAttributes: true, SupportsVariations: false, IsMultivalued: true, PossibleValues: []models.BuyerTaxonomyPropertyValue{{ValueID: 3001, Name: "Walnut"}, {ValueID: 3002, Name: "Maple"}, {ValueID: 3003, Name: "Cherry"}, {ValueID: 3004, Name: "Oak"}}, SelectedValues: []models.BuyerTaxonomyPropertyValue{}, Scales: []models.BuyerTaxonomyPropertyScale{}},
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dataFileVersion is bumped whenever the on-disk layout changes in a way
+// older servers cannot read.
+const dataFileVersion = 1
+
+// dataFile is the versioned document written by SaveFile.
+type dataFile struct {
+	Version int             `json:"version"`
+	SavedAt int64           `json:"saved_timestamp"`
+	Store   json.RawMessage `json:"store"`
+}
+
+// SaveFile writes the store to path. The document is written to a temporary
+// file in the same directory and renamed into place, so a crash mid-write
+// never leaves a truncated file behind.
+func (s *Store) SaveFile(path string) error {
+	data, err := s.Dump()
+	if err != nil {
+		return err
+	}
+	doc, err := json.MarshalIndent(dataFile{Version: dataFileVersion, SavedAt: now(), Store: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(doc); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile replaces the contents of the store with a document written by
+// SaveFile. A missing file yields an error satisfying errors.Is(err, os.ErrNotExist).
+func (s *Store) LoadFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc dataFile
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+	if doc.Version != dataFileVersion {
+		return fmt.Errorf("%s: unsupported data file version %d (want %d)", path, doc.Version, dataFileVersion)
+	}
+	if len(doc.Store) == 0 {
+		return fmt.Errorf("%s: missing store", path)
+	}
+	if err := s.Load(doc.Store); err != nil {
+		return fmt.Errorf("load %s: %w", path, err)
+	}
+	return nil
+}
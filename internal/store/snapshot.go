@@ -0,0 +1,169 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/vlah-software-house/etsy-mock-api/internal/models"
+)
+
+// state is the serializable form of a Store. Encoding a Store through it
+// yields a deep copy that shares no pointers with the live data.
+type state struct {
+	Shops                     map[int64]*models.Shop                          `json:"shops"`
+	ShopSections              map[int64]*models.ShopSection                   `json:"shop_sections"`
+	ShopReturnPolicies        map[int64]*models.ShopReturnPolicy              `json:"shop_return_policies"`
+	Listings                  map[int64]*models.ShopListing                   `json:"listings"`
+	ListingImages             map[int64][]*models.ListingImage                `json:"listing_images"`
+	ListingFiles              map[int64][]*models.ListingFile                 `json:"listing_files"`
+	Receipts                  map[int64]*models.ShopReceipt                   `json:"receipts"`
+	Transactions              map[int64]*models.ShopReceiptTransaction        `json:"transactions"`
+	Payments                  map[int64]*models.Payment                       `json:"payments"`
+	Users                     map[int64]*models.User                          `json:"users"`
+	UserAddresses             map[int64][]*models.UserAddress                 `json:"user_addresses"`
+	Reviews                   map[int64][]*models.ListingReview               `json:"reviews"`
+	ShippingProfiles          map[int64]*models.ShopShippingProfile           `json:"shipping_profiles"`
+	LedgerEntries             map[int64][]*models.PaymentAccountLedgerEntry   `json:"ledger_entries"`
+	ListingProperties         map[int64][]models.ListingPropertyValue         `json:"listing_properties"`
+	ListingTranslations       map[int64]map[string]*models.ListingTranslation `json:"listing_translations"`
+	ListingVariationImages    map[int64][]models.ListingVariationImage        `json:"listing_variation_images"`
+	ReadinessStateDefinitions map[int64]*models.ReadinessStateDefinition      `json:"readiness_state_definitions"`
+	TaxonomyNodes             []models.BuyerTaxonomyNode                      `json:"taxonomy_nodes"`
+	TaxonomyProperties        map[int64][]models.BuyerTaxonomyNodeProperty    `json:"taxonomy_properties"`
+	NextID                    int64                                           `json:"next_id"`
+}
+
+// Dump serializes the full contents of the store.
+func (s *Store) Dump() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return json.Marshal(state{
+		Shops:                     s.Shops,
+		ShopSections:              s.ShopSections,
+		ShopReturnPolicies:        s.ShopReturnPolicies,
+		Listings:                  s.Listings,
+		ListingImages:             s.ListingImages,
+		ListingFiles:              s.ListingFiles,
+		Receipts:                  s.Receipts,
+		Transactions:              s.Transactions,
+		Payments:                  s.Payments,
+		Users:                     s.Users,
+		UserAddresses:             s.UserAddresses,
+		Reviews:                   s.Reviews,
+		ShippingProfiles:          s.ShippingProfiles,
+		LedgerEntries:             s.LedgerEntries,
+		ListingProperties:         s.ListingProperties,
+		ListingTranslations:       s.ListingTranslations,
+		ListingVariationImages:    s.ListingVariationImages,
+		ReadinessStateDefinitions: s.ReadinessStateDefinitions,
+		TaxonomyNodes:             s.TaxonomyNodes,
+		TaxonomyProperties:        s.TaxonomyProperties,
+		NextID:                    s.nextID,
+	})
+}
+
+// Load replaces the contents of the store with data produced by Dump.
+// On error the store is left untouched.
+func (s *Store) Load(data []byte) error {
+	fresh := New()
+	st := state{
+		Shops:                     fresh.Shops,
+		ShopSections:              fresh.ShopSections,
+		ShopReturnPolicies:        fresh.ShopReturnPolicies,
+		Listings:                  fresh.Listings,
+		ListingImages:             fresh.ListingImages,
+		ListingFiles:              fresh.ListingFiles,
+		Receipts:                  fresh.Receipts,
+		Transactions:              fresh.Transactions,
+		Payments:                  fresh.Payments,
+		Users:                     fresh.Users,
+		UserAddresses:             fresh.UserAddresses,
+		Reviews:                   fresh.Reviews,
+		ShippingProfiles:          fresh.ShippingProfiles,
+		LedgerEntries:             fresh.LedgerEntries,
+		ListingProperties:         fresh.ListingProperties,
+		ListingTranslations:       fresh.ListingTranslations,
+		ListingVariationImages:    fresh.ListingVariationImages,
+		ReadinessStateDefinitions: fresh.ReadinessStateDefinitions,
+		TaxonomyProperties:        fresh.TaxonomyProperties,
+		NextID:                    fresh.nextID,
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return err
+	}
+	fresh.TaxonomyNodes = st.TaxonomyNodes
+	fresh.nextID = st.NextID
+	s.Replace(fresh)
+	return nil
+}
+
+// SnapshotInfo describes a saved snapshot.
+type SnapshotInfo struct {
+	Name      string `json:"name"`
+	CreatedAt int64  `json:"created_timestamp"`
+	SizeBytes int    `json:"size_bytes"`
+}
+
+type snapshot struct {
+	info SnapshotInfo
+	data []byte
+}
+
+// Snapshots holds named copies of store contents. Thread-safe.
+type Snapshots struct {
+	mu    sync.RWMutex
+	saved map[string]snapshot
+}
+
+func NewSnapshots() *Snapshots {
+	return &Snapshots{saved: make(map[string]snapshot)}
+}
+
+// Save captures the current contents of s under name, replacing any
+// snapshot already saved with that name.
+func (ss *Snapshots) Save(name string, s *Store) (SnapshotInfo, error) {
+	data, err := s.Dump()
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	info := SnapshotInfo{Name: name, CreatedAt: now(), SizeBytes: len(data)}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.saved[name] = snapshot{info: info, data: data}
+	return info, nil
+}
+
+// Restore loads the named snapshot into s. It reports false if no such
+// snapshot exists.
+func (ss *Snapshots) Restore(name string, s *Store) (bool, error) {
+	ss.mu.RLock()
+	snap, ok := ss.saved[name]
+	ss.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, s.Load(snap.data)
+}
+
+// List returns all saved snapshots ordered by name.
+func (ss *Snapshots) List() []SnapshotInfo {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+	infos := make([]SnapshotInfo, 0, len(ss.saved))
+	for _, snap := range ss.saved {
+		infos = append(infos, snap.info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+func (ss *Snapshots) Delete(name string) bool {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if _, ok := ss.saved[name]; !ok {
+		return false
+	}
+	delete(ss.saved, name)
+	return true
+}
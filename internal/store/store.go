@@ -2,10 +2,12 @@ package store
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
-	"time"
 
+	"github.com/vlah-software-house/etsy-mock-api/internal/clock"
 	"github.com/vlah-software-house/etsy-mock-api/internal/models"
 )
 
@@ -26,6 +28,10 @@ type Store struct {
 	Reviews           map[int64][]*models.ListingReview // keyed by shop_id
 	ShippingProfiles  map[int64]*models.ShopShippingProfile
 	LedgerEntries     map[int64][]*models.PaymentAccountLedgerEntry // keyed by shop_id
+	ListingProperties map[int64][]models.ListingPropertyValue // keyed by listing_id
+	ListingTranslations map[int64]map[string]*models.ListingTranslation // keyed by listing_id, then language
+	ListingVariationImages map[int64][]models.ListingVariationImage // keyed by listing_id
+	ReadinessStateDefinitions map[int64]*models.ReadinessStateDefinition // keyed by readiness_state_id
 	TaxonomyNodes     []models.BuyerTaxonomyNode
 	TaxonomyProperties map[int64][]models.BuyerTaxonomyNodeProperty // keyed by taxonomy_id
 
@@ -48,6 +54,10 @@ func New() *Store {
 		Reviews:            make(map[int64][]*models.ListingReview),
 		ShippingProfiles:   make(map[int64]*models.ShopShippingProfile),
 		LedgerEntries:      make(map[int64][]*models.PaymentAccountLedgerEntry),
+		ListingProperties:  make(map[int64][]models.ListingPropertyValue),
+		ListingTranslations: make(map[int64]map[string]*models.ListingTranslation),
+		ListingVariationImages: make(map[int64][]models.ListingVariationImage),
+		ReadinessStateDefinitions: make(map[int64]*models.ReadinessStateDefinition),
 		TaxonomyProperties: make(map[int64][]models.BuyerTaxonomyNodeProperty),
 		nextID:             10000,
 	}
@@ -60,8 +70,37 @@ func (s *Store) NextID() int64 {
 	return s.nextID
 }
 
+// Replace swaps the contents of s for those of src under s's lock, so
+// concurrent requests see either the old data set or the new one, never a mix.
+// src must not be used after the call.
+func (s *Store) Replace(src *Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Shops = src.Shops
+	s.ShopSections = src.ShopSections
+	s.ShopReturnPolicies = src.ShopReturnPolicies
+	s.Listings = src.Listings
+	s.ListingImages = src.ListingImages
+	s.ListingFiles = src.ListingFiles
+	s.Receipts = src.Receipts
+	s.Transactions = src.Transactions
+	s.Payments = src.Payments
+	s.Users = src.Users
+	s.UserAddresses = src.UserAddresses
+	s.Reviews = src.Reviews
+	s.ShippingProfiles = src.ShippingProfiles
+	s.LedgerEntries = src.LedgerEntries
+	s.ListingProperties = src.ListingProperties
+	s.ListingTranslations = src.ListingTranslations
+	s.ListingVariationImages = src.ListingVariationImages
+	s.ReadinessStateDefinitions = src.ReadinessStateDefinitions
+	s.TaxonomyNodes = src.TaxonomyNodes
+	s.TaxonomyProperties = src.TaxonomyProperties
+	s.nextID = src.nextID
+}
+
 func now() int64 {
-	return time.Now().Unix()
+	return clock.Now().Unix()
 }
 
 // Shop operations
@@ -84,6 +123,19 @@ func (s *Store) GetShopByName(name string) (*models.Shop, bool) {
 	return nil, false
 }
 
+// GetShopByOwner returns the shop owned by userID. A user owns at most one
+// shop.
+func (s *Store) GetShopByOwner(userID int64) (*models.Shop, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, shop := range s.Shops {
+		if shop.UserID == userID {
+			return shop, true
+		}
+	}
+	return nil, false
+}
+
 func (s *Store) UpdateShop(shop *models.Shop) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -100,9 +152,15 @@ func (s *Store) GetShopSections(shopID int64) []models.ShopSection {
 	var sections []models.ShopSection
 	for _, sec := range s.ShopSections {
 		if sec.UserID == shopID {
-			sections = append(sections, *sec)
+			sections = append(sections, s.sectionWithCount(sec))
 		}
 	}
+	sort.Slice(sections, func(i, j int) bool {
+		if sections[i].Rank != sections[j].Rank {
+			return sections[i].Rank < sections[j].Rank
+		}
+		return sections[i].ShopSectionID < sections[j].ShopSectionID
+	})
 	return sections
 }
 
@@ -111,11 +169,82 @@ func (s *Store) GetShopSection(shopID, sectionID int64) (*models.ShopSection, bo
 	defer s.mu.RUnlock()
 	sec, ok := s.ShopSections[sectionID]
 	if ok && sec.UserID == shopID {
-		return sec, true
+		c := s.sectionWithCount(sec)
+		return &c, true
 	}
 	return nil, false
 }
 
+// sectionWithCount returns a copy of sec with ActiveListingCount counted from
+// the listings, so the count stays correct however a listing's section or
+// state changes. The caller must hold the lock.
+func (s *Store) sectionWithCount(sec *models.ShopSection) models.ShopSection {
+	c := *sec
+	c.ActiveListingCount = 0
+	for _, l := range s.Listings {
+		if l.State == "active" && l.ShopSectionID != nil && *l.ShopSectionID == sec.ShopSectionID {
+			c.ActiveListingCount++
+		}
+	}
+	return c
+}
+
+func (s *Store) UpdateShopSection(shopID, sectionID int64, title string) (*models.ShopSection, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sec, ok := s.ShopSections[sectionID]
+	if !ok || sec.UserID != shopID {
+		return nil, false
+	}
+	sec.Title = title
+	c := s.sectionWithCount(sec)
+	return &c, true
+}
+
+// DeleteShopSection removes a section. Listings in it are left without a section.
+func (s *Store) DeleteShopSection(shopID, sectionID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sec, ok := s.ShopSections[sectionID]
+	if !ok || sec.UserID != shopID {
+		return false
+	}
+	delete(s.ShopSections, sectionID)
+	for _, l := range s.Listings {
+		if l.ShopSectionID != nil && *l.ShopSectionID == sectionID {
+			l.ShopSectionID = nil
+		}
+	}
+	return true
+}
+
+// GetListingsByShopSections returns the shop's active listings that are in
+// any of sectionIDs, sorted and then paginated.
+func (s *Store) GetListingsByShopSections(shopID int64, sectionIDs []int64, limit, offset int, sortOn, sortOrder string) ([]models.ShopListing, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	wanted := make(map[int64]bool, len(sectionIDs))
+	for _, id := range sectionIDs {
+		wanted[id] = true
+	}
+	var all []models.ShopListing
+	for _, l := range s.Listings {
+		if l.ShopID == shopID && l.State == "active" && l.ShopSectionID != nil && wanted[*l.ShopSectionID] {
+			all = append(all, *l)
+		}
+	}
+	sortListings(all, sortOn, sortOrder)
+	total := len(all)
+	if offset >= total {
+		return nil, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return all[offset:end], total
+}
+
 func (s *Store) CreateShopSection(shopID int64, title string, rank int) *models.ShopSection {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -167,6 +296,90 @@ func (s *Store) CreateReturnPolicy(shopID int64, acceptsReturns, acceptsExchange
 	return p
 }
 
+// FindIdenticalReturnPolicy returns a policy of the shop, other than
+// excludeID, with exactly the given settings.
+func (s *Store) FindIdenticalReturnPolicy(shopID, excludeID int64, acceptsReturns, acceptsExchanges bool, deadline *int) (*models.ShopReturnPolicy, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.ShopReturnPolicies {
+		if p.ShopID != shopID || p.ReturnPolicyID == excludeID {
+			continue
+		}
+		if p.AcceptsReturns != acceptsReturns || p.AcceptsExchanges != acceptsExchanges {
+			continue
+		}
+		if (p.ReturnDeadline == nil) != (deadline == nil) {
+			continue
+		}
+		if deadline != nil && *p.ReturnDeadline != *deadline {
+			continue
+		}
+		return p, true
+	}
+	return nil, false
+}
+
+func (s *Store) UpdateReturnPolicy(policyID int64, acceptsReturns, acceptsExchanges bool, deadline *int) (*models.ShopReturnPolicy, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShopReturnPolicies[policyID]
+	if !ok {
+		return nil, false
+	}
+	p.AcceptsReturns = acceptsReturns
+	p.AcceptsExchanges = acceptsExchanges
+	p.ReturnDeadline = deadline
+	return p, true
+}
+
+func (s *Store) DeleteReturnPolicy(policyID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ShopReturnPolicies[policyID]; !ok {
+		return false
+	}
+	delete(s.ShopReturnPolicies, policyID)
+	return true
+}
+
+// GetListingsByReturnPolicy returns every listing, in any state, that uses the policy.
+func (s *Store) GetListingsByReturnPolicy(policyID int64) []models.ShopListing {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := []models.ShopListing{}
+	for _, l := range s.Listings {
+		if l.ReturnPolicyID != nil && *l.ReturnPolicyID == policyID {
+			all = append(all, *l)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ListingID < all[j].ListingID })
+	return all
+}
+
+// ConsolidateReturnPolicies moves every listing using sourceID to destID and
+// deletes the source policy.
+func (s *Store) ConsolidateReturnPolicies(sourceID, destID int64) (*models.ShopReturnPolicy, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dest, ok := s.ShopReturnPolicies[destID]
+	if !ok {
+		return nil, false
+	}
+	if _, ok := s.ShopReturnPolicies[sourceID]; !ok {
+		return nil, false
+	}
+	for _, l := range s.Listings {
+		if l.ReturnPolicyID != nil && *l.ReturnPolicyID == sourceID {
+			id := destID
+			l.ReturnPolicyID = &id
+			l.LastModifiedTimestamp = now()
+			l.UpdatedTimestamp = now()
+		}
+	}
+	delete(s.ShopReturnPolicies, sourceID)
+	return dest, true
+}
+
 // Listing operations
 
 func (s *Store) GetListing(listingID int64) (*models.ShopListing, bool) {
@@ -219,6 +432,7 @@ func (s *Store) GetActiveListings(keyword string, taxonomyID *int, limit, offset
 		}
 		all = append(all, *l)
 	}
+	sortListings(all, sortOn, sortOrder)
 	total := len(all)
 	if offset >= total {
 		return nil, total
@@ -230,6 +444,42 @@ func (s *Store) GetActiveListings(keyword string, taxonomyID *int, limit, offset
 	return all[offset:end], total
 }
 
+// sortListings orders listings by sortOn ("created", "price", "updated" or
+// "score") in sortOrder. Score is always descending, as on Etsy. Ties are
+// broken by listing ID so pages are stable.
+func sortListings(listings []models.ShopListing, sortOn, sortOrder string) {
+	desc := true
+	switch sortOrder {
+	case "asc", "ascending", "up":
+		desc = false
+	}
+	key := func(l models.ShopListing) int64 {
+		switch sortOn {
+		case "price":
+			return int64(l.Price.Amount)
+		case "updated":
+			return l.UpdatedTimestamp
+		case "score":
+			return int64(l.NumFavorers)
+		default:
+			return l.CreatedTimestamp
+		}
+	}
+	if sortOn == "score" {
+		desc = true
+	}
+	sort.SliceStable(listings, func(i, j int) bool {
+		a, b := key(listings[i]), key(listings[j])
+		if a == b {
+			return listings[i].ListingID < listings[j].ListingID
+		}
+		if desc {
+			return a > b
+		}
+		return a < b
+	})
+}
+
 func containsTag(tags []string, keyword string) bool {
 	for _, t := range tags {
 		if strings.Contains(strings.ToLower(t), keyword) {
@@ -273,6 +523,7 @@ func (s *Store) CreateListing(shopID int64, req models.CreateListingRequest) *mo
 		Materials:                 req.Materials,
 		ShippingProfileID:         req.ShippingProfileID,
 		ReturnPolicyID:            req.ReturnPolicyID,
+		ShopSectionID:             req.ShopSectionID,
 		WhoMade:                   &req.WhoMade,
 		WhenMade:                  &req.WhenMade,
 		IsSupply:                  req.IsSupply,
@@ -342,6 +593,13 @@ func (s *Store) UpdateListing(listingID int64, req models.UpdateListingRequest)
 	if req.ReturnPolicyID != nil {
 		l.ReturnPolicyID = req.ReturnPolicyID
 	}
+	if req.ShopSectionID != nil {
+		if *req.ShopSectionID == 0 {
+			l.ShopSectionID = nil
+		} else {
+			l.ShopSectionID = req.ShopSectionID
+		}
+	}
 	if req.IsSupply != nil {
 		l.IsSupply = req.IsSupply
 	}
@@ -383,6 +641,9 @@ func (s *Store) DeleteListing(listingID int64) bool {
 	delete(s.Listings, listingID)
 	delete(s.ListingImages, listingID)
 	delete(s.ListingFiles, listingID)
+	delete(s.ListingProperties, listingID)
+	delete(s.ListingTranslations, listingID)
+	delete(s.ListingVariationImages, listingID)
 	return true
 }
 
@@ -428,12 +689,26 @@ func (s *Store) DeleteListingImage(listingID, imageID int64) bool {
 	for i, img := range imgs {
 		if img.ListingImageID == imageID {
 			s.ListingImages[listingID] = append(imgs[:i], imgs[i+1:]...)
+			s.dropVariationImage(listingID, imageID)
 			return true
 		}
 	}
 	return false
 }
 
+// dropVariationImage removes every variation image mapping that points at
+// imageID. The caller must hold the write lock.
+func (s *Store) dropVariationImage(listingID, imageID int64) {
+	vis := s.ListingVariationImages[listingID]
+	kept := vis[:0]
+	for _, vi := range vis {
+		if vi.ImageID != imageID {
+			kept = append(kept, vi)
+		}
+	}
+	s.ListingVariationImages[listingID] = kept
+}
+
 // Listing File operations
 
 func (s *Store) GetListingFiles(listingID int64) []models.ListingFile {
@@ -564,10 +839,45 @@ func (s *Store) GetShopTransactions(shopID int64, limit, offset int) ([]models.S
 	defer s.mu.RUnlock()
 	var all []models.ShopReceiptTransaction
 	for _, t := range s.Transactions {
-		if t.SellerUserID == shopID {
+		if s.shopOwnsTransaction(shopID, t) {
+			all = append(all, *t)
+		}
+	}
+	return paginateTransactions(all, limit, offset)
+}
+
+// GetListingTransactions returns the shop's sales of a listing, oldest
+// first.
+func (s *Store) GetListingTransactions(shopID, listingID int64, limit, offset int) ([]models.ShopReceiptTransaction, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var all []models.ShopReceiptTransaction
+	for _, t := range s.Transactions {
+		if t.ListingID != nil && int64(*t.ListingID) == listingID && s.shopOwnsTransaction(shopID, t) {
 			all = append(all, *t)
 		}
 	}
+	return paginateTransactions(all, limit, offset)
+}
+
+// GetReceiptListings returns each listing bought on a receipt once, in
+// listing ID order. Listings deleted since the sale are left out.
+func (s *Store) GetReceiptListings(receiptID int64, limit, offset int) ([]models.ShopListing, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[int64]bool)
+	var all []models.ShopListing
+	for _, t := range s.Transactions {
+		if t.ReceiptID != receiptID || t.ListingID == nil {
+			continue
+		}
+		id := int64(*t.ListingID)
+		if l, ok := s.Listings[id]; ok && !seen[id] {
+			seen[id] = true
+			all = append(all, *l)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ListingID < all[j].ListingID })
 	total := len(all)
 	if offset >= total {
 		return nil, total
@@ -579,6 +889,25 @@ func (s *Store) GetShopTransactions(shopID int64, limit, offset int) ([]models.S
 	return all[offset:end], total
 }
 
+func (s *Store) shopOwnsTransaction(shopID int64, t *models.ShopReceiptTransaction) bool {
+	shop, ok := s.Shops[shopID]
+	return ok && shop.UserID == t.SellerUserID
+}
+
+// paginateTransactions orders txns by ID so pages are stable across calls.
+func paginateTransactions(txns []models.ShopReceiptTransaction, limit, offset int) ([]models.ShopReceiptTransaction, int) {
+	sort.Slice(txns, func(i, j int) bool { return txns[i].TransactionID < txns[j].TransactionID })
+	total := len(txns)
+	if offset >= total {
+		return nil, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return txns[offset:end], total
+}
+
 // Payment operations
 
 func (s *Store) GetPaymentsByReceipt(receiptID int64) []models.Payment {
@@ -593,6 +922,23 @@ func (s *Store) GetPaymentsByReceipt(receiptID int64) []models.Payment {
 	return payments
 }
 
+// GetPayments returns the shop's payments among paymentIDs, in payment ID
+// order. IDs of other shops' payments are ignored.
+func (s *Store) GetPayments(shopID int64, paymentIDs []int64) []models.Payment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[int64]bool, len(paymentIDs))
+	var payments []models.Payment
+	for _, id := range paymentIDs {
+		if p, ok := s.Payments[id]; ok && p.ShopID == shopID && !seen[id] {
+			seen[id] = true
+			payments = append(payments, *p)
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].PaymentID < payments[j].PaymentID })
+	return payments
+}
+
 func (s *Store) GetPaymentsByShop(shopID int64) []models.Payment {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -614,15 +960,63 @@ func (s *Store) GetUser(userID int64) (*models.User, bool) {
 	return u, ok
 }
 
-func (s *Store) GetUserAddresses(userID int64) []models.UserAddress {
+// GetUsers returns every user, ordered by ID.
+func (s *Store) GetUsers() []models.User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := make([]models.User, 0, len(s.Users))
+	for _, u := range s.Users {
+		users = append(users, *u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].UserID < users[j].UserID })
+	return users
+}
+
+func (s *Store) GetUserAddresses(userID int64, limit, offset int) ([]models.UserAddress, int) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	addrs := s.UserAddresses[userID]
-	result := make([]models.UserAddress, len(addrs))
-	for i, a := range addrs {
+	total := len(addrs)
+	if offset >= total {
+		return nil, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	result := make([]models.UserAddress, end-offset)
+	for i, a := range addrs[offset:end] {
 		result[i] = *a
 	}
-	return result
+	return result, total
+}
+
+func (s *Store) GetUserAddress(addressID int64) (*models.UserAddress, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, addrs := range s.UserAddresses {
+		for _, a := range addrs {
+			if a.UserAddressID == addressID {
+				addr := *a
+				return &addr, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (s *Store) DeleteUserAddress(addressID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for userID, addrs := range s.UserAddresses {
+		for i, a := range addrs {
+			if a.UserAddressID == addressID {
+				s.UserAddresses[userID] = append(addrs[:i], addrs[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Review operations
@@ -687,7 +1081,7 @@ func (s *Store) GetShopShippingProfiles(shopID int64) []models.ShopShippingProfi
 	var profiles []models.ShopShippingProfile
 	for _, p := range s.ShippingProfiles {
 		shop := s.Shops[shopID]
-		if shop != nil && p.UserID == shop.UserID {
+		if shop != nil && p.UserID == shop.UserID && !p.IsDeleted {
 			profiles = append(profiles, *p)
 		}
 	}
@@ -723,12 +1117,176 @@ func (s *Store) DeleteShippingProfile(profileID int64) bool {
 	return true
 }
 
+func (s *Store) UpdateShippingProfile(profileID int64, req models.UpdateShippingProfileRequest) (*models.ShopShippingProfile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return nil, false
+	}
+	if req.Title != nil {
+		p.Title = req.Title
+	}
+	if req.OriginCountryISO != nil {
+		p.OriginCountryISO = *req.OriginCountryISO
+		for i := range p.ShippingProfileDestinations {
+			p.ShippingProfileDestinations[i].OriginCountryISO = *req.OriginCountryISO
+		}
+	}
+	if req.OriginPostalCode != nil {
+		p.OriginPostalCode = req.OriginPostalCode
+	}
+	return p, true
+}
+
+// Shipping profile destination operations
+
+func (s *Store) GetShippingDestinations(profileID int64, limit, offset int) ([]models.ShopShippingProfileDestination, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return nil, 0
+	}
+	total := len(p.ShippingProfileDestinations)
+	if offset >= total {
+		return nil, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	result := make([]models.ShopShippingProfileDestination, end-offset)
+	copy(result, p.ShippingProfileDestinations[offset:end])
+	return result, total
+}
+
+func (s *Store) AddShippingDestination(profileID int64, d models.ShopShippingProfileDestination) (*models.ShopShippingProfileDestination, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return nil, false
+	}
+	s.nextID++
+	d.ShippingProfileDestinationID = s.nextID
+	d.ShippingProfileID = profileID
+	d.OriginCountryISO = p.OriginCountryISO
+	p.ShippingProfileDestinations = append(p.ShippingProfileDestinations, d)
+	return &d, true
+}
+
+// UpdateShippingDestination replaces the destination with d's ID.
+func (s *Store) UpdateShippingDestination(profileID int64, d models.ShopShippingProfileDestination) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return false
+	}
+	for i := range p.ShippingProfileDestinations {
+		if p.ShippingProfileDestinations[i].ShippingProfileDestinationID == d.ShippingProfileDestinationID {
+			p.ShippingProfileDestinations[i] = d
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) DeleteShippingDestination(profileID, destinationID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return false
+	}
+	for i, d := range p.ShippingProfileDestinations {
+		if d.ShippingProfileDestinationID == destinationID {
+			p.ShippingProfileDestinations = append(p.ShippingProfileDestinations[:i], p.ShippingProfileDestinations[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Shipping profile upgrade operations
+
+func (s *Store) GetShippingUpgrades(profileID int64) []models.ShopShippingProfileUpgrade {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return nil
+	}
+	result := make([]models.ShopShippingProfileUpgrade, len(p.ShippingProfileUpgrades))
+	copy(result, p.ShippingProfileUpgrades)
+	return result
+}
+
+func (s *Store) AddShippingUpgrade(profileID int64, u models.ShopShippingProfileUpgrade) (*models.ShopShippingProfileUpgrade, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return nil, false
+	}
+	s.nextID++
+	u.UpgradeID = s.nextID
+	u.ShippingProfileID = profileID
+	u.Rank = len(p.ShippingProfileUpgrades) + 1
+	if u.Language == "" {
+		u.Language = "en"
+	}
+	p.ShippingProfileUpgrades = append(p.ShippingProfileUpgrades, u)
+	return &u, true
+}
+
+// UpdateShippingUpgrade replaces the upgrade with u's ID.
+func (s *Store) UpdateShippingUpgrade(profileID int64, u models.ShopShippingProfileUpgrade) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return false
+	}
+	for i := range p.ShippingProfileUpgrades {
+		if p.ShippingProfileUpgrades[i].UpgradeID == u.UpgradeID {
+			p.ShippingProfileUpgrades[i] = u
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) DeleteShippingUpgrade(profileID, upgradeID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.ShippingProfiles[profileID]
+	if !ok {
+		return false
+	}
+	for i, u := range p.ShippingProfileUpgrades {
+		if u.UpgradeID == upgradeID {
+			p.ShippingProfileUpgrades = append(p.ShippingProfileUpgrades[:i], p.ShippingProfileUpgrades[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // Ledger operations
 
-func (s *Store) GetLedgerEntries(shopID int64, limit, offset int) ([]models.PaymentAccountLedgerEntry, int) {
+// GetLedgerEntries returns the shop's ledger entries created between
+// minCreated and maxCreated inclusive.
+func (s *Store) GetLedgerEntries(shopID, minCreated, maxCreated int64, limit, offset int) ([]models.PaymentAccountLedgerEntry, int) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	all := s.LedgerEntries[shopID]
+	var all []*models.PaymentAccountLedgerEntry
+	for _, e := range s.LedgerEntries[shopID] {
+		if e.CreatedTimestamp >= minCreated && e.CreatedTimestamp <= maxCreated {
+			all = append(all, e)
+		}
+	}
 	total := len(all)
 	if offset >= total {
 		return nil, total
@@ -744,6 +1302,55 @@ func (s *Store) GetLedgerEntries(shopID int64, limit, offset int) ([]models.Paym
 	return result, total
 }
 
+func (s *Store) GetLedgerEntry(shopID, entryID int64) (*models.PaymentAccountLedgerEntry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.LedgerEntries[shopID] {
+		if e.EntryID == entryID {
+			entry := *e
+			return &entry, true
+		}
+	}
+	return nil, false
+}
+
+// GetLedgerEntryPayments returns the shop's payments referenced by the given
+// ledger entries, each once, in payment ID order. An entry references a
+// payment either directly (reference_type "payment") or through the receipt
+// the payment settled (reference_type "receipt"); other entry types, such as
+// fees, reference no payment.
+func (s *Store) GetLedgerEntryPayments(shopID int64, entryIDs []int64) []models.Payment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	wanted := make(map[int64]bool, len(entryIDs))
+	for _, id := range entryIDs {
+		wanted[id] = true
+	}
+	seen := make(map[int64]bool)
+	var payments []models.Payment
+	for _, e := range s.LedgerEntries[shopID] {
+		if !wanted[e.EntryID] || e.ReferenceID == nil {
+			continue
+		}
+		refID, err := strconv.ParseInt(*e.ReferenceID, 10, 64)
+		if err != nil {
+			continue
+		}
+		for _, p := range s.Payments {
+			if p.ShopID != shopID || seen[p.PaymentID] {
+				continue
+			}
+			if (e.ReferenceType == "payment" && p.PaymentID == refID) ||
+				(e.ReferenceType == "receipt" && p.ReceiptID == refID) {
+				seen[p.PaymentID] = true
+				payments = append(payments, *p)
+			}
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].PaymentID < payments[j].PaymentID })
+	return payments
+}
+
 // Taxonomy operations
 
 func (s *Store) GetTaxonomyNodes() []models.BuyerTaxonomyNode {
@@ -768,19 +1375,24 @@ func (s *Store) GetListingInventory(listingID int64) (*models.ListingInventory,
 	if !ok {
 		return nil, false
 	}
+	return listingInventory(l), true
+}
+
+// listingInventory returns the stored inventory of a listing, or a default
+// single-product inventory derived from the listing if none has been set.
+func listingInventory(l *models.ShopListing) *models.ListingInventory {
 	if l.Inventory != nil {
-		return l.Inventory, true
+		return l.Inventory
 	}
-	// Return a default inventory based on the listing
 	return &models.ListingInventory{
 		Products: []models.ListingInventoryProduct{
 			{
-				ProductID: listingID * 10,
+				ProductID: l.ListingID * 10,
 				SKU:       "",
 				IsDeleted: false,
 				Offerings: []models.ListingInventoryProductOffering{
 					{
-						OfferingID: listingID * 100,
+						OfferingID: l.ListingID * 100,
 						Quantity:   l.Quantity,
 						IsEnabled:  true,
 						IsDeleted:  false,
@@ -790,8 +1402,325 @@ func (s *Store) GetListingInventory(listingID int64) (*models.ListingInventory,
 				PropertyValues: []models.ListingPropertyValue{},
 			},
 		},
-		PriceOnProperty:    []int{},
-		QuantityOnProperty: []int{},
-		SKUOnProperty:      []int{},
-	}, true
+		PriceOnProperty:          []int{},
+		QuantityOnProperty:       []int{},
+		SKUOnProperty:            []int{},
+		ReadinessStateOnProperty: []int{},
+	}
+}
+
+// GetListingProduct looks up a product in a listing's inventory by its ID.
+// Deleted products are not found.
+func (s *Store) GetListingProduct(listingID, productID int64) (*models.ListingInventoryProduct, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	l, ok := s.Listings[listingID]
+	if !ok {
+		return nil, false
+	}
+	return findProduct(listingInventory(l), productID)
+}
+
+// GetListingOffering looks up an offering of a product in a listing's
+// inventory. Deleted offerings, and offerings of deleted products, are not found.
+func (s *Store) GetListingOffering(listingID, productID, offeringID int64) (*models.ListingInventoryProductOffering, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	l, ok := s.Listings[listingID]
+	if !ok {
+		return nil, false
+	}
+	p, ok := findProduct(listingInventory(l), productID)
+	if !ok {
+		return nil, false
+	}
+	for i := range p.Offerings {
+		o := &p.Offerings[i]
+		if o.OfferingID == offeringID && !o.IsDeleted {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+func findProduct(inv *models.ListingInventory, productID int64) (*models.ListingInventoryProduct, bool) {
+	for i := range inv.Products {
+		p := &inv.Products[i]
+		if p.ProductID == productID && !p.IsDeleted {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// UpdateListingInventory replaces a listing's inventory. Products whose
+// property values match a product already on the listing keep their product
+// and offering IDs; all others are assigned new ones. The listing's quantity,
+// price, SKUs and has_variations are derived from the new offerings.
+func (s *Store) UpdateListingInventory(listingID int64, inv models.ListingInventory) (*models.ListingInventory, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.Listings[listingID]
+	if !ok {
+		return nil, false
+	}
+
+	existing := make(map[string]*models.ListingInventoryProduct)
+	current := listingInventory(l)
+	for i := range current.Products {
+		p := &current.Products[i]
+		existing[p.Combination()] = p
+	}
+
+	quantity := 0
+	var price *models.Money
+	skus := []string{}
+	hasVariations := false
+	for i := range inv.Products {
+		p := &inv.Products[i]
+		prev := existing[p.Combination()]
+		if prev != nil {
+			p.ProductID = prev.ProductID
+		} else {
+			s.nextID++
+			p.ProductID = s.nextID
+		}
+		for j := range p.Offerings {
+			o := &p.Offerings[j]
+			if prev != nil && j < len(prev.Offerings) {
+				o.OfferingID = prev.Offerings[j].OfferingID
+			} else {
+				s.nextID++
+				o.OfferingID = s.nextID
+			}
+			if o.IsEnabled {
+				quantity += o.Quantity
+				if price == nil || o.Price.Amount < price.Amount {
+					price = &o.Price
+				}
+			}
+		}
+		if p.SKU != "" {
+			skus = append(skus, p.SKU)
+		}
+		if len(p.PropertyValues) > 0 {
+			hasVariations = true
+		}
+	}
+
+	l.Inventory = &inv
+	l.Quantity = quantity
+	if price != nil {
+		l.Price = *price
+	}
+	l.SKUs = skus
+	l.HasVariations = hasVariations
+	l.LastModifiedTimestamp = now()
+	l.UpdatedTimestamp = now()
+	return l.Inventory, true
+}
+
+// Listing property operations
+
+func (s *Store) GetListingProperties(listingID int64) []models.ListingPropertyValue {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	props := s.ListingProperties[listingID]
+	result := make([]models.ListingPropertyValue, len(props))
+	copy(result, props)
+	return result
+}
+
+func (s *Store) GetListingProperty(listingID, propertyID int64) (*models.ListingPropertyValue, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, pv := range s.ListingProperties[listingID] {
+		if pv.PropertyID == propertyID {
+			return &pv, true
+		}
+	}
+	return nil, false
+}
+
+// SetListingProperty stores pv on the listing, replacing any value already
+// set for the same property.
+func (s *Store) SetListingProperty(listingID int64, pv models.ListingPropertyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.Listings[listingID]; ok {
+		l.LastModifiedTimestamp = now()
+		l.UpdatedTimestamp = now()
+	}
+	props := s.ListingProperties[listingID]
+	for i := range props {
+		if props[i].PropertyID == pv.PropertyID {
+			props[i] = pv
+			return
+		}
+	}
+	s.ListingProperties[listingID] = append(props, pv)
+}
+
+func (s *Store) DeleteListingProperty(listingID, propertyID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	props := s.ListingProperties[listingID]
+	for i, pv := range props {
+		if pv.PropertyID == propertyID {
+			s.ListingProperties[listingID] = append(props[:i], props[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Listing translation operations
+
+func (s *Store) GetListingTranslation(listingID int64, language string) (*models.ListingTranslation, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.ListingTranslations[listingID][language]
+	return t, ok
+}
+
+// CreateListingTranslation stores t. It returns false, storing nothing, if
+// the listing already has a translation for t.Language.
+func (s *Store) CreateListingTranslation(t *models.ListingTranslation) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byLang := s.ListingTranslations[t.ListingID]
+	if byLang == nil {
+		byLang = make(map[string]*models.ListingTranslation)
+		s.ListingTranslations[t.ListingID] = byLang
+	}
+	if _, exists := byLang[t.Language]; exists {
+		return false
+	}
+	byLang[t.Language] = t
+	return true
+}
+
+// UpdateListingTranslation replaces an existing translation. It returns false
+// if the listing has no translation for t.Language.
+func (s *Store) UpdateListingTranslation(t *models.ListingTranslation) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byLang := s.ListingTranslations[t.ListingID]
+	if _, exists := byLang[t.Language]; !exists {
+		return false
+	}
+	byLang[t.Language] = t
+	return true
+}
+
+// Listing variation image operations
+
+func (s *Store) GetListingVariationImages(listingID int64) []models.ListingVariationImage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	vis := s.ListingVariationImages[listingID]
+	result := make([]models.ListingVariationImage, len(vis))
+	copy(result, vis)
+	return result
+}
+
+// SetListingVariationImages replaces all variation image mappings on a listing.
+func (s *Store) SetListingVariationImages(listingID int64, vis []models.ListingVariationImage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ListingVariationImages[listingID] = vis
+}
+
+// Readiness state definition operations
+
+func (s *Store) GetReadinessStateDefinitions(shopID int64, limit, offset int) ([]models.ReadinessStateDefinition, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var all []models.ReadinessStateDefinition
+	for _, d := range s.ReadinessStateDefinitions {
+		if d.ShopID == shopID {
+			all = append(all, *d)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ReadinessStateID < all[j].ReadinessStateID })
+	total := len(all)
+	if offset >= total {
+		return nil, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return all[offset:end], total
+}
+
+func (s *Store) GetReadinessStateDefinition(id int64) (*models.ReadinessStateDefinition, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.ReadinessStateDefinitions[id]
+	return d, ok
+}
+
+// FindIdenticalReadinessStateDefinition returns a definition of the shop,
+// other than excludeID, with the same state and processing days.
+func (s *Store) FindIdenticalReadinessStateDefinition(shopID, excludeID int64, state string, minDays, maxDays int) (*models.ReadinessStateDefinition, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, d := range s.ReadinessStateDefinitions {
+		if d.ShopID == shopID && d.ReadinessStateID != excludeID && d.ReadinessState == state &&
+			d.MinProcessingDays == minDays && d.MaxProcessingDays == maxDays {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Store) CreateReadinessStateDefinition(d *models.ReadinessStateDefinition) *models.ReadinessStateDefinition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	d.ReadinessStateID = s.nextID
+	s.ReadinessStateDefinitions[d.ReadinessStateID] = d
+	return d
+}
+
+func (s *Store) UpdateReadinessStateDefinition(d *models.ReadinessStateDefinition) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ReadinessStateDefinitions[d.ReadinessStateID]; !ok {
+		return false
+	}
+	s.ReadinessStateDefinitions[d.ReadinessStateID] = d
+	return true
+}
+
+func (s *Store) DeleteReadinessStateDefinition(id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ReadinessStateDefinitions[id]; !ok {
+		return false
+	}
+	delete(s.ReadinessStateDefinitions, id)
+	return true
+}
+
+// CountReadinessStateReferences returns how many inventory offerings, across
+// all listings, use the readiness state definition.
+func (s *Store) CountReadinessStateReferences(id int64) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, l := range s.Listings {
+		if l.Inventory == nil {
+			continue
+		}
+		for _, p := range l.Inventory.Products {
+			for _, o := range p.Offerings {
+				if o.ReadinessStateID != nil && *o.ReadinessStateID == id && !p.IsDeleted && !o.IsDeleted {
+					n++
+				}
+			}
+		}
+	}
+	return n
 }
@@ -0,0 +1,89 @@
+package store
+
+import "github.com/vlah-software-house/etsy-mock-api/internal/models"
+
+// Delays after which simulated orders move on by themselves.
+const (
+	receiptPaymentDelay = 3600      // an open receipt is paid an hour after it was placed
+	receiptSettleDelay  = 3 * 86400 // a shipped receipt completes three days after its last shipment
+)
+
+// TimeEffects counts what CatchUp changed.
+type TimeEffects struct {
+	ExpiredListings   int `json:"expired_listings"`
+	PaidReceipts      int `json:"paid_receipts"`
+	CompletedReceipts int `json:"completed_receipts"`
+	SettledPayments   int `json:"settled_payments"`
+}
+
+// CatchUp applies everything that should have happened by the current time
+// of the clock: active listings past their ending timestamp expire, open
+// receipts get paid, and shipped receipts complete with their payments
+// settled. It is run whenever the clock is moved.
+func (s *Store) CatchUp() TimeEffects {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ts := now()
+	var fx TimeEffects
+
+	for _, l := range s.Listings {
+		if l.State == "active" && l.EndingTimestamp > 0 && l.EndingTimestamp <= ts {
+			l.State = "expired"
+			l.LastModifiedTimestamp = ts
+			l.UpdatedTimestamp = ts
+			fx.ExpiredListings++
+		}
+	}
+
+	for _, r := range s.Receipts {
+		if r.Status == "open" && !r.IsPaid && r.CreateTimestamp+receiptPaymentDelay <= ts {
+			r.Status = "paid"
+			r.IsPaid = true
+			r.UpdateTimestamp = ts
+			r.UpdatedTimestamp = ts
+			paidAt := r.CreateTimestamp + receiptPaymentDelay
+			for i := range r.Transactions {
+				s.markTransactionPaid(&r.Transactions[i], paidAt)
+			}
+			fx.PaidReceipts++
+		}
+
+		shippedAt, shipped := lastShipment(r)
+		if r.Status == "paid" && r.IsShipped && shipped && shippedAt+receiptSettleDelay <= ts {
+			r.Status = "completed"
+			r.UpdateTimestamp = ts
+			r.UpdatedTimestamp = ts
+			fx.CompletedReceipts++
+			for _, p := range s.Payments {
+				if p.ReceiptID == r.ReceiptID && p.Status == "open" {
+					p.Status = "settled"
+					p.UpdateTimestamp = ts
+					p.UpdatedTimestamp = ts
+					fx.SettledPayments++
+				}
+			}
+		}
+	}
+	return fx
+}
+
+// markTransactionPaid stamps a receipt's copy of a transaction and the
+// store's own copy, if they differ. The caller must hold the write lock.
+func (s *Store) markTransactionPaid(t *models.ShopReceiptTransaction, paidAt int64) {
+	if t.PaidTimestamp == nil {
+		t.PaidTimestamp = &paidAt
+	}
+	if st, ok := s.Transactions[t.TransactionID]; ok && st != t && st.PaidTimestamp == nil {
+		st.PaidTimestamp = &paidAt
+	}
+}
+
+func lastShipment(r *models.ShopReceipt) (int64, bool) {
+	var last int64
+	for _, sh := range r.Shipments {
+		if sh.ShipmentNotificationTimestamp > last {
+			last = sh.ShipmentNotificationTimestamp
+		}
+	}
+	return last, len(r.Shipments) > 0
+}